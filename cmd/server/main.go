@@ -29,6 +29,7 @@ func main() {
 	configPath := flag.String("f", "", "Configuration file path, default is ./data/config.json")
 	version := flag.Bool("version", false, "Display version information")
 	port := flag.Int("port", 0, "Specify server port, overrides config file")
+	migrate := flag.Bool("migrate", false, "Apply pending database migrations even if auto_migrate is disabled")
 	flag.Parse()
 
 	if *version {
@@ -56,6 +57,7 @@ func main() {
 	cfg, err := config.Load(*configPath)
 	if err != nil {
 		logger.Error("Configuration loading failed: %s", err)
+		os.Exit(1)
 	}
 
 	if _, err := os.Stat(*configPath); err == nil {
@@ -68,6 +70,10 @@ func main() {
 		logger.Info("Using command line specified port: %d", *port)
 	}
 
+	if *migrate {
+		cfg.Database.AutoMigrate = true
+	}
+
 	srv := server.NewServer(cfg)
 	if err := srv.Start(); err != nil {
 		logger.Error("Server startup failed: %s", err)
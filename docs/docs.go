@@ -17,7 +17,7 @@ const docTemplate = `{
     "paths": {
         "/api/health": {
             "get": {
-                "description": "获取服务器健康状态",
+                "description": "获取服务器健康状态，format=standard时使用统一响应结构，否则返回兼容旧版的扁平结构",
                 "produces": [
                     "application/json"
                 ],
@@ -25,19 +25,68 @@ const docTemplate = `{
                     "系统"
                 ],
                 "summary": "健康检查",
+                "parameters": [
+                    {
+                        "enum": [
+                            "standard"
+                        ],
+                        "type": "string",
+                        "description": "响应格式",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "服务器健康",
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "status": {
-                                    "type": "string"
-                                },
-                                "time": {
-                                    "type": "string"
-                                }
-                            }
+                            "$ref": "#/definitions/handler.HealthStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/health/live": {
+            "get": {
+                "description": "进程能够响应请求即返回200",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统"
+                ],
+                "summary": "存活探针",
+                "responses": {
+                    "200": {
+                        "description": "进程存活",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HealthStatus"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/health/ready": {
+            "get": {
+                "description": "检查数据库是否可用以及是否存在未应用的迁移，全部检查通过时返回200，否则返回503",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统"
+                ],
+                "summary": "就绪探针",
+                "responses": {
+                    "200": {
+                        "description": "服务就绪",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ReadinessStatus"
+                        }
+                    },
+                    "503": {
+                        "description": "服务未就绪",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ReadinessStatus"
                         }
                     }
                 }
@@ -50,7 +99,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "使用提供的URL创建新订阅",
+                "description": "使用提供的URL创建新订阅，未提供cron时使用配置中的默认cron，携带相同Idempotency-Key的重试请求将返回首次创建的订阅，并发的重复请求会等待首次请求完成",
                 "consumes": [
                     "application/json"
                 ],
@@ -62,6 +111,12 @@ const docTemplate = `{
                 ],
                 "summary": "创建新订阅",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "幂等键",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
                     {
                         "description": "订阅数据",
                         "name": "sub",
@@ -104,7 +159,7 @@ const docTemplate = `{
                         }
                     },
                     "409": {
-                        "description": "订阅已存在",
+                        "description": "订阅已存在或相同幂等键的请求仍在处理中",
                         "schema": {
                             "$ref": "#/definitions/model.ConflictResponse"
                         }
@@ -118,14 +173,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/sub/list": {
-            "get": {
+        "/api/sub/import-url": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取所有订阅的列表",
+                "description": "获取每行一个订阅URL的远程列表并逐行创建订阅，返回每行的导入结果，空行和#开头的行将被忽略",
                 "consumes": [
                     "application/json"
                 ],
@@ -135,10 +190,21 @@ const docTemplate = `{
                 "tags": [
                     "订阅"
                 ],
-                "summary": "获取所有订阅",
+                "summary": "从远程列表导入订阅",
+                "parameters": [
+                    {
+                        "description": "导入列表",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ImportURLRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
-                        "description": "成功",
+                        "description": "订阅已导入",
                         "schema": {
                             "allOf": [
                                 {
@@ -148,39 +214,42 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/model.Sub"
-                                            }
+                                            "$ref": "#/definitions/handler.ImportURLResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "无效请求",
+                        "schema": {
+                            "$ref": "#/definitions/model.BadRequestResponse"
+                        }
+                    },
                     "401": {
                         "description": "未授权",
                         "schema": {
                             "$ref": "#/definitions/model.UnauthorizedResponse"
                         }
                     },
-                    "500": {
-                        "description": "服务器错误",
+                    "502": {
+                        "description": "获取导入列表失败",
                         "schema": {
-                            "$ref": "#/definitions/model.ServerErrorResponse"
+                            "$ref": "#/definitions/model.StandardResponse"
                         }
                     }
                 }
             }
         },
-        "/api/sub/{id}": {
+        "/api/sub/list": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "根据ID获取订阅详情",
+                "description": "获取所有订阅的列表，可按存活节点数过滤。传入after_id或limit时按ID进行游标分页，返回SubPage结构",
                 "consumes": [
                     "application/json"
                 ],
@@ -190,14 +259,25 @@ const docTemplate = `{
                 "tags": [
                     "订阅"
                 ],
-                "summary": "获取订阅详情",
+                "summary": "获取所有订阅",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "订阅ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "最少存活节点数",
+                        "name": "min_alive",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "游标，返回ID大于该值的订阅",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量，默认20，最大100",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -212,7 +292,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Sub"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Sub"
+                                            }
                                         }
                                     }
                                 }
@@ -231,12 +314,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.UnauthorizedResponse"
                         }
                     },
-                    "404": {
-                        "description": "订阅不存在",
-                        "schema": {
-                            "$ref": "#/definitions/model.NotFoundResponse"
-                        }
-                    },
                     "500": {
                         "description": "服务器错误",
                         "schema": {
@@ -244,14 +321,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/sub/reorder": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "更新订阅URL",
+                "description": "按给定的ID顺序更新订阅的排序，未列出的订阅保持原有相对顺序排在其后，列表和合并输出均遵循该顺序",
                 "consumes": [
                     "application/json"
                 ],
@@ -261,28 +340,21 @@ const docTemplate = `{
                 "tags": [
                     "订阅"
                 ],
-                "summary": "更新订阅",
+                "summary": "调整订阅顺序",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "订阅ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "更新的订阅数据",
-                        "name": "sub",
+                        "description": "按新顺序排列的订阅ID",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.UpdateSubRequest"
+                            "$ref": "#/definitions/handler.ReorderSubsRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "订阅已更新",
+                        "description": "成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -292,7 +364,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.Sub"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.Sub"
+                                            }
                                         }
                                     }
                                 }
@@ -324,44 +399,43 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/sub/schedule": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "根据ID删除订阅",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "返回所有自动更新订阅的cron、上次运行时间以及根据cron计算的下次运行时间，按下次运行时间排序",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "订阅"
                 ],
-                "summary": "删除订阅",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "订阅ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "获取订阅更新计划",
                 "responses": {
                     "200": {
-                        "description": "订阅已删除",
-                        "schema": {
-                            "$ref": "#/definitions/model.SuccessResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "无效请求",
+                        "description": "成功",
                         "schema": {
-                            "$ref": "#/definitions/model.BadRequestResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/handler.SubScheduleEntry"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "401": {
@@ -370,12 +444,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.UnauthorizedResponse"
                         }
                     },
-                    "404": {
-                        "description": "订阅不存在",
-                        "schema": {
-                            "$ref": "#/definitions/model.NotFoundResponse"
-                        }
-                    },
                     "500": {
                         "description": "服务器错误",
                         "schema": {
@@ -385,14 +453,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/sub/{id}/content": {
+        "/api/sub/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "从订阅URL中获取内容并存储到内存中",
+                "description": "根据ID获取订阅详情",
                 "consumes": [
                     "application/json"
                 ],
@@ -402,7 +470,7 @@ const docTemplate = `{
                 "tags": [
                     "订阅"
                 ],
-                "summary": "获取订阅内容",
+                "summary": "获取订阅详情",
                 "parameters": [
                     {
                         "type": "integer",
@@ -437,10 +505,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.BadRequestResponse"
                         }
                     },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
                     "404": {
                         "description": "订阅不存在",
                         "schema": {
-                            "$ref": "#/definitions/model.ServerErrorResponse"
+                            "$ref": "#/definitions/model.NotFoundResponse"
                         }
                     },
                     "500": {
@@ -450,16 +524,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/user/info": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取当前登录用户的信息",
+                "description": "更新订阅，空字符串或未提供的字段保持不变",
                 "consumes": [
                     "application/json"
                 ],
@@ -467,12 +539,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "用户"
+                    "订阅"
+                ],
+                "summary": "更新订阅",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "订阅ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新的订阅数据",
+                        "name": "sub",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateSubRequest"
+                        }
+                    }
                 ],
-                "summary": "获取用户信息",
                 "responses": {
                     "200": {
-                        "description": "成功",
+                        "description": "订阅已更新",
                         "schema": {
                             "allOf": [
                                 {
@@ -482,13 +572,19 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/model.User"
+                                            "$ref": "#/definitions/model.Sub"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "无效请求",
+                        "schema": {
+                            "$ref": "#/definitions/model.BadRequestResponse"
+                        }
+                    },
                     "401": {
                         "description": "未授权",
                         "schema": {
@@ -496,7 +592,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "用户不存在",
+                        "description": "订阅不存在",
                         "schema": {
                             "$ref": "#/definitions/model.NotFoundResponse"
                         }
@@ -509,13 +605,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "put": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "更新用户信息（用户名、密码）",
+                "description": "根据ID删除订阅",
                 "consumes": [
                     "application/json"
                 ],
@@ -523,41 +619,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "用户"
+                    "订阅"
                 ],
-                "summary": "更新用户信息",
+                "summary": "删除订阅",
                 "parameters": [
                     {
-                        "description": "更新用户信息请求参数",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.UpdateUserInfoRequest"
-                        }
+                        "type": "integer",
+                        "description": "订阅ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "更新成功",
+                        "description": "订阅已删除",
                         "schema": {
                             "$ref": "#/definitions/model.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "无效的请求参数",
+                        "description": "无效请求",
                         "schema": {
                             "$ref": "#/definitions/model.BadRequestResponse"
                         }
                     },
                     "401": {
-                        "description": "未授权或旧密码错误",
+                        "description": "未授权",
                         "schema": {
                             "$ref": "#/definitions/model.UnauthorizedResponse"
                         }
                     },
                     "404": {
-                        "description": "用户不存在",
+                        "description": "订阅不存在",
                         "schema": {
                             "$ref": "#/definitions/model.NotFoundResponse"
                         }
@@ -569,8 +663,1239 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "仅更新请求体中出现的字段，未出现的字段保持不变",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "订阅"
+                ],
+                "summary": "部分更新订阅",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "订阅ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "需要更新的字段",
+                        "name": "sub",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.PatchSubRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "订阅已更新",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Sub"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "无效请求",
+                        "schema": {
+                            "$ref": "#/definitions/model.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "订阅不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sub/{id}/content": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "从订阅URL中获取内容并存储到内存中，会更新订阅的获取状态，维护模式下不可用",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "订阅"
+                ],
+                "summary": "获取订阅内容",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "订阅ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "获取超时秒数，不超过配置的上限",
+                        "name": "X-Timeout-Seconds",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Sub"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "无效请求",
+                        "schema": {
+                            "$ref": "#/definitions/model.BadRequestResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "订阅不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "获取失败，或订阅返回空内容、内容不符合成功匹配规则，已保留之前的内容",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "维护模式已开启",
+                        "schema": {
+                            "$ref": "#/definitions/model.StandardResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "获取订阅超时",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sub/{id}/load-snapshot": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "将快照文件内容恢复到内存中的订阅内容，未指定名称时加载最新快照，并像获取一样更新订阅的获取时间和内容大小",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "订阅"
+                ],
+                "summary": "加载订阅内容快照",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "订阅ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "快照名称",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.LoadSnapshotRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "快照已加载",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.ContentSnapshotResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "无效请求",
+                        "schema": {
+                            "$ref": "#/definitions/model.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "需要管理员权限",
+                        "schema": {
+                            "$ref": "#/definitions/model.StandardResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "订阅或快照不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sub/{id}/pin": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "固定当前订阅内容并持久化保存，之后的获取仅记录获取时间而不覆盖已存储的内容，固定的内容不会因过期而被清理，重启后首次获取时从持久化副本恢复",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "订阅"
+                ],
+                "summary": "固定订阅内容",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "订阅ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "订阅内容已固定",
+                        "schema": {
+                            "$ref": "#/definitions/model.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "无效请求",
+                        "schema": {
+                            "$ref": "#/definitions/model.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "订阅或订阅内容不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sub/{id}/snapshot": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "将内存中的订阅内容保存为数据目录下带时间戳的文件，便于离线复现解析问题",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "订阅"
+                ],
+                "summary": "保存订阅内容快照",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "订阅ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "快照已保存",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.ContentSnapshotResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "无效请求",
+                        "schema": {
+                            "$ref": "#/definitions/model.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "需要管理员权限",
+                        "schema": {
+                            "$ref": "#/definitions/model.StandardResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "订阅内容不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sub/{id}/stats": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "手动设置订阅的节点总数和存活数，用于测试和修正，存活数不能大于总数",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "订阅"
+                ],
+                "summary": "设置订阅节点统计",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "订阅ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "节点统计",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateStatsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "统计已更新",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Sub"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "无效请求",
+                        "schema": {
+                            "$ref": "#/definitions/model.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "订阅不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sub/{id}/unpin": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "取消固定并删除持久化的固定内容，下一次获取将重新覆盖存储的订阅内容",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "订阅"
+                ],
+                "summary": "取消固定订阅内容",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "订阅ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "订阅内容已取消固定",
+                        "schema": {
+                            "$ref": "#/definitions/model.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "无效请求",
+                        "schema": {
+                            "$ref": "#/definitions/model.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "订阅不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/system/audit": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "分页获取用户操作审计日志，按时间倒序",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统"
+                ],
+                "summary": "获取审计日志",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "每页数量，默认20，最大100",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "偏移量",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.AuditLogListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "无效请求",
+                        "schema": {
+                            "$ref": "#/definitions/model.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "需要管理员权限",
+                        "schema": {
+                            "$ref": "#/definitions/model.StandardResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/system/config": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取命令行参数覆盖后的当前运行配置，JWT密钥等敏感字段已脱敏，仅管理员可用",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统"
+                ],
+                "summary": "获取运行配置",
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.Config"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "需要管理员权限",
+                        "schema": {
+                            "$ref": "#/definitions/model.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/system/db-stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取数据库连接池的实时统计信息，仅管理员可用",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统"
+                ],
+                "summary": "获取数据库连接池统计",
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.DBStats"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "需要管理员权限",
+                        "schema": {
+                            "$ref": "#/definitions/model.StandardResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "运行时调整最大打开连接数和最大空闲连接数，重启后恢复默认值，仅管理员可用",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统"
+                ],
+                "summary": "调整数据库连接池",
+                "parameters": [
+                    {
+                        "description": "连接池限制",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SetDBPoolRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "已调整",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.DBStats"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "无效请求",
+                        "schema": {
+                            "$ref": "#/definitions/model.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "需要管理员权限",
+                        "schema": {
+                            "$ref": "#/definitions/model.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/system/jwt/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "生成新的JWT密钥并写入配置文件，所有已签发的令牌立即失效",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统"
+                ],
+                "summary": "重置JWT密钥",
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "$ref": "#/definitions/model.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "需要管理员权限",
+                        "schema": {
+                            "$ref": "#/definitions/model.StandardResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/system/maintenance": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取当前是否处于只读维护模式",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统"
+                ],
+                "summary": "获取维护模式状态",
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.MaintenanceStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "开启或关闭只读维护模式，开启后除登录外的写操作均返回503",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统"
+                ],
+                "summary": "设置维护模式",
+                "parameters": [
+                    {
+                        "description": "维护模式开关",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SetMaintenanceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.MaintenanceStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "无效请求",
+                        "schema": {
+                            "$ref": "#/definitions/model.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "需要管理员权限",
+                        "schema": {
+                            "$ref": "#/definitions/model.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/system/migrate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "仅应用到指定版本为止的待执行迁移，目标版本必须大于当前版本，仅管理员可用",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统"
+                ],
+                "summary": "迁移数据库到指定版本",
+                "parameters": [
+                    {
+                        "description": "目标版本",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.MigrateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "迁移完成",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.MigrateResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "无效的目标版本",
+                        "schema": {
+                            "$ref": "#/definitions/model.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "需要管理员权限",
+                        "schema": {
+                            "$ref": "#/definitions/model.StandardResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/system/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取运行时统计信息，如正在进行的订阅获取数量和订阅内容总大小",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统"
+                ],
+                "summary": "获取运行统计",
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.SystemStats"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/system/validate-cron": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "校验cron表达式并返回接下来的几次执行时间",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "系统"
+                ],
+                "summary": "校验cron表达式",
+                "parameters": [
+                    {
+                        "description": "cron表达式",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.ValidateCronRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/handler.ValidateCronResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "无效请求",
+                        "schema": {
+                            "$ref": "#/definitions/model.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/user/info": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取当前登录用户的信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户"
+                ],
+                "summary": "获取用户信息",
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "更新用户信息（用户名、密码）",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户"
+                ],
+                "summary": "更新用户信息",
+                "parameters": [
+                    {
+                        "description": "更新用户信息请求参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateUserInfoRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "更新成功",
+                        "schema": {
+                            "$ref": "#/definitions/model.SuccessResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "无效的请求参数",
+                        "schema": {
+                            "$ref": "#/definitions/model.BadRequestResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权或旧密码错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.UnauthorizedResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ServerErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/api/user/login": {
             "post": {
                 "description": "用户登录并获取JWT令牌",
@@ -674,14 +1999,137 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "handler.CreateSubRequest": {
+        }
+    },
+    "definitions": {
+        "handler.AuditLogListResponse": {
+            "type": "object"
+        },
+        "handler.ContentSnapshotResponse": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "sub-1-20240101-000000.000000.txt"
+                }
+            }
+        },
+        "handler.CreateSubRequest": {
+            "type": "object",
+            "required": [
+                "auto_update",
+                "url"
+            ],
+            "properties": {
+                "auto_update": {
+                    "type": "boolean"
+                },
+                "cron": {
+                    "type": "string"
+                },
+                "fetch_body": {
+                    "description": "FetchBody Sent with POST fetches, JSON bodies get a JSON content type, anything else is sent as a form",
+                    "type": "string",
+                    "maxLength": 4096
+                },
+                "fetch_method": {
+                    "description": "FetchMethod GET (default) or POST",
+                    "type": "string",
+                    "enum": [
+                        "GET",
+                        "POST"
+                    ]
+                },
+                "fetch_timeout_seconds": {
+                    "description": "FetchTimeoutSeconds 0 uses the global fetcher timeout",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 256
+                },
+                "success_pattern": {
+                    "description": "SuccessPattern Regex the fetched content must match, empty accepts any 200 response",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "url_vars": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handler.DBStats": {
+            "type": "object",
+            "properties": {
+                "idle": {
+                    "type": "integer"
+                },
+                "in_use": {
+                    "type": "integer"
+                },
+                "max_idle_closed": {
+                    "type": "integer"
+                },
+                "max_idle_time_closed": {
+                    "type": "integer"
+                },
+                "max_lifetime_closed": {
+                    "type": "integer"
+                },
+                "max_open_connections": {
+                    "type": "integer"
+                },
+                "open_connections": {
+                    "type": "integer"
+                },
+                "wait_count": {
+                    "type": "integer"
+                },
+                "wait_duration_ms": {
+                    "description": "WaitDurationMs Total time spent waiting for a connection in milliseconds",
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.HealthStatus": {
+            "type": "object",
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                },
+                "time": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                }
+            }
+        },
+        "handler.ImportLineResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "sub_id": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ImportURLRequest": {
             "type": "object",
             "required": [
-                "auto_update",
-                "cron",
                 "url"
             ],
             "properties": {
@@ -692,6 +2140,32 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "url": {
+                    "description": "URL List of subscription URLs, one per line. Blank lines and lines starting with # are skipped",
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ImportURLResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ImportLineResult"
+                    }
+                }
+            }
+        },
+        "handler.LoadSnapshotRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
                     "type": "string"
                 }
             }
@@ -728,6 +2202,208 @@ const docTemplate = `{
                 }
             }
         },
+        "handler.MaintenanceStatus": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handler.MigrateRequest": {
+            "type": "object",
+            "required": [
+                "version"
+            ],
+            "properties": {
+                "version": {
+                    "description": "Version Target version, migrations up to and including it are applied",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 2
+                }
+            }
+        },
+        "handler.MigrateResponse": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.PatchSubRequest": {
+            "type": "object",
+            "properties": {
+                "auto_update": {
+                    "type": "boolean"
+                },
+                "cron": {
+                    "type": "string"
+                },
+                "fetch_body": {
+                    "description": "FetchBody An empty string removes the body",
+                    "type": "string",
+                    "maxLength": 4096
+                },
+                "fetch_method": {
+                    "type": "string",
+                    "enum": [
+                        "GET",
+                        "POST"
+                    ]
+                },
+                "fetch_timeout_seconds": {
+                    "description": "FetchTimeoutSeconds 0 resets to the global fetcher timeout",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "remark": {
+                    "description": "Remark An empty string clears the remark",
+                    "type": "string",
+                    "maxLength": 256
+                },
+                "success_pattern": {
+                    "description": "SuccessPattern An empty string removes the pattern",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "minLength": 1
+                },
+                "url_vars": {
+                    "description": "URLVars Replaces all variables, a value of \"***\" keeps the stored value",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "handler.ReadinessStatus": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                },
+                "time": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                }
+            }
+        },
+        "handler.ReorderSubsRequest": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "description": "IDs Numbers or numeric strings",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "handler.SetDBPoolRequest": {
+            "type": "object",
+            "properties": {
+                "max_idle_conns": {
+                    "description": "MaxIdleConns 0 disables idle connections",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "max_open_conns": {
+                    "description": "MaxOpenConns 0 means unlimited",
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "handler.SetMaintenanceRequest": {
+            "type": "object",
+            "required": [
+                "enabled"
+            ],
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "handler.SubScheduleEntry": {
+            "type": "object",
+            "properties": {
+                "cron": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_run": {
+                    "description": "LastRun Time of the last successful fetch",
+                    "type": "string"
+                },
+                "next_run": {
+                    "description": "NextRun Next time the cron fires, null when the stored cron is invalid",
+                    "type": "string"
+                },
+                "remark": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.SystemStats": {
+            "type": "object",
+            "properties": {
+                "active_fetches": {
+                    "type": "integer"
+                },
+                "total_content_size": {
+                    "description": "TotalContentSize Sum of the last fetched content size of all subscriptions in bytes",
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.UpdateStatsRequest": {
+            "type": "object",
+            "required": [
+                "alive_nodes",
+                "total_nodes"
+            ],
+            "properties": {
+                "alive_nodes": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "total_nodes": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
         "handler.UpdateSubRequest": {
             "type": "object",
             "properties": {
@@ -737,8 +2413,38 @@ const docTemplate = `{
                 "cron": {
                     "type": "string"
                 },
+                "fetch_body": {
+                    "type": "string",
+                    "maxLength": 4096
+                },
+                "fetch_method": {
+                    "type": "string",
+                    "enum": [
+                        "GET",
+                        "POST"
+                    ]
+                },
+                "fetch_timeout_seconds": {
+                    "description": "FetchTimeoutSeconds 0 resets to the global fetcher timeout",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "remark": {
+                    "type": "string",
+                    "maxLength": 256
+                },
+                "success_pattern": {
+                    "type": "string"
+                },
                 "url": {
                     "type": "string"
+                },
+                "url_vars": {
+                    "description": "URLVars Replaces all variables, a value of \"***\" keeps the stored value",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 }
             }
         },
@@ -757,6 +2463,34 @@ const docTemplate = `{
                 }
             }
         },
+        "handler.ValidateCronRequest": {
+            "type": "object",
+            "required": [
+                "cron"
+            ],
+            "properties": {
+                "cron": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ValidateCronResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "next_runs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
         "model.BadRequestResponse": {
             "type": "object",
             "properties": {
@@ -771,6 +2505,187 @@ const docTemplate = `{
                 }
             }
         },
+        "model.Config": {
+            "type": "object",
+            "properties": {
+                "content_store": {
+                    "type": "object",
+                    "properties": {
+                        "max_age_seconds": {
+                            "description": "MaxAgeSeconds Cached content of unpinned subs not fetched for this long is evicted, 0 disables the sweep",
+                            "type": "integer"
+                        }
+                    }
+                },
+                "database": {
+                    "type": "object",
+                    "properties": {
+                        "audit_retention_days": {
+                            "description": "AuditRetentionDays Audit log entries older than this are deleted during maintenance, 0 keeps them forever",
+                            "type": "integer"
+                        },
+                        "auto_migrate": {
+                            "type": "boolean"
+                        },
+                        "maintenance_interval_hours": {
+                            "description": "MaintenanceIntervalHours Interval of audit log pruning and vacuuming, 0 disables maintenance",
+                            "type": "integer"
+                        },
+                        "path": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "fetcher": {
+                    "type": "object",
+                    "properties": {
+                        "dial_timeout_seconds": {
+                            "description": "DialTimeoutSeconds Limit for establishing the TCP connection",
+                            "type": "integer"
+                        },
+                        "keep_previous_on_empty": {
+                            "description": "KeepPreviousOnEmpty Rejects empty fetch results so the previously stored content is kept",
+                            "type": "boolean"
+                        },
+                        "max_concurrent_per_host": {
+                            "type": "integer"
+                        },
+                        "max_redirects": {
+                            "description": "MaxRedirects Redirect chains are stopped once they reach this many requests, 0 disables redirects",
+                            "type": "integer"
+                        },
+                        "timeout_seconds": {
+                            "description": "TimeoutSeconds Overall limit for a fetch including reading the body",
+                            "type": "integer"
+                        },
+                        "tls_handshake_timeout_seconds": {
+                            "description": "TLSHandshakeTimeoutSeconds Limit for the TLS handshake",
+                            "type": "integer"
+                        }
+                    }
+                },
+                "jwt": {
+                    "type": "object",
+                    "properties": {
+                        "allow_insecure": {
+                            "type": "boolean"
+                        },
+                        "expires_in": {
+                            "type": "integer"
+                        },
+                        "secret": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "password": {
+                    "type": "object",
+                    "properties": {
+                        "algorithm": {
+                            "description": "Algorithm Hash used for new passwords, bcrypt or argon2id. Hashes of the other algorithm still verify\nand are rehashed on the next successful login",
+                            "type": "string"
+                        },
+                        "bcrypt_cost": {
+                            "description": "BcryptCost Work factor of new bcrypt hashes, 0 uses bcrypt's default. Hashes with another cost are\nrehashed on the next successful login",
+                            "type": "integer"
+                        }
+                    }
+                },
+                "scheduler": {
+                    "type": "object",
+                    "properties": {
+                        "default_cron": {
+                            "type": "string"
+                        },
+                        "validate_on_startup": {
+                            "description": "ValidateOnStartup Checks the cron of every stored sub at startup and reports invalid ones",
+                            "type": "boolean"
+                        }
+                    }
+                },
+                "server": {
+                    "type": "object",
+                    "properties": {
+                        "admin_allowed_cidrs": {
+                            "description": "AdminAllowedCIDRs IPs or CIDRs allowed to reach admin endpoints, empty allows all",
+                            "type": "array",
+                            "items": {
+                                "type": "string"
+                            }
+                        },
+                        "api_only": {
+                            "type": "boolean"
+                        },
+                        "case_insensitive_routes": {
+                            "description": "CaseInsensitiveRoutes Redirects paths that only differ in case or extra slashes to the routed path",
+                            "type": "boolean"
+                        },
+                        "host": {
+                            "type": "string"
+                        },
+                        "log_bodies": {
+                            "description": "LogBodies Logs request and response bodies (size-capped, secrets redacted) while the log level is debug",
+                            "type": "boolean"
+                        },
+                        "maintenance_mode": {
+                            "type": "boolean"
+                        },
+                        "max_concurrent_per_ip": {
+                            "description": "MaxConcurrentPerIP Requests a single client IP may have in flight, 0 disables the limit",
+                            "type": "integer"
+                        },
+                        "max_request_timeout_seconds": {
+                            "description": "MaxRequestTimeoutSeconds Upper bound for the X-Timeout-Seconds request header, 0 ignores the header",
+                            "type": "integer"
+                        },
+                        "port": {
+                            "type": "integer"
+                        },
+                        "redirect_trailing_slash": {
+                            "description": "RedirectTrailingSlash Redirects /path/ to /path (and back) when only the other form is routed",
+                            "type": "boolean"
+                        },
+                        "route_timeouts": {
+                            "description": "RouteTimeouts Handler timeout in seconds per route group path, e.g. {\"/api/sub\": 60}, requests over it get 504",
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        },
+                        "tls": {
+                            "description": "TLS Serves HTTPS when both files are set, certificates are reloaded when the files change",
+                            "type": "object",
+                            "properties": {
+                                "auto_cert": {
+                                    "description": "AutoCert Obtains certificates from Let's Encrypt for Domains, port 80 is bound for the HTTP-01 challenge",
+                                    "type": "object",
+                                    "properties": {
+                                        "cache_dir": {
+                                            "type": "string"
+                                        },
+                                        "domains": {
+                                            "type": "array",
+                                            "items": {
+                                                "type": "string"
+                                            }
+                                        },
+                                        "email": {
+                                            "type": "string"
+                                        }
+                                    }
+                                },
+                                "cert_file": {
+                                    "type": "string"
+                                },
+                                "key_file": {
+                                    "type": "string"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "model.ConflictResponse": {
             "type": "object",
             "properties": {
@@ -813,6 +2728,18 @@ const docTemplate = `{
                 }
             }
         },
+        "model.StandardResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "data": {},
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
         "model.Sub": {
             "type": "object",
             "properties": {
@@ -822,12 +2749,28 @@ const docTemplate = `{
                 "auto_update": {
                     "type": "boolean"
                 },
+                "content_size": {
+                    "description": "ContentSize Byte size of the content from the last successful fetch",
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "cron": {
                     "type": "string"
                 },
+                "fetch_body": {
+                    "description": "FetchBody Request body sent with POST fetches",
+                    "type": "string"
+                },
+                "fetch_method": {
+                    "description": "FetchMethod HTTP method used to fetch the subscription, GET or POST",
+                    "type": "string"
+                },
+                "fetch_timeout_seconds": {
+                    "description": "FetchTimeoutSeconds Overrides the global fetch timeout when greater than zero",
+                    "type": "integer"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -837,6 +2780,21 @@ const docTemplate = `{
                 "last_fetch": {
                     "type": "string"
                 },
+                "pinned": {
+                    "description": "Pinned Fetches still run but keep the stored content until the sub is unpinned",
+                    "type": "boolean"
+                },
+                "remark": {
+                    "description": "Remark Free-form user note describing the subscription",
+                    "type": "string"
+                },
+                "sort_order": {
+                    "type": "integer"
+                },
+                "success_pattern": {
+                    "description": "SuccessPattern Regex fetched content must match for the fetch to count as successful",
+                    "type": "string"
+                },
                 "total_nodes": {
                     "type": "integer"
                 },
@@ -845,6 +2803,14 @@ const docTemplate = `{
                 },
                 "url": {
                     "type": "string"
+                },
+                "url_vars": {
+                    "description": "URLVars Values substituted into {{.Name}} placeholders of URL at fetch time",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.URLVars"
+                        }
+                    ]
                 }
             }
         },
@@ -862,6 +2828,12 @@ const docTemplate = `{
                 }
             }
         },
+        "model.URLVars": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "string"
+            }
+        },
         "model.UnauthorizedResponse": {
             "type": "object",
             "properties": {
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/bestruirui/bestsub/internal/logger"
+	"github.com/bestruirui/bestsub/internal/model"
+)
+
+// InsecureJWTSecret Publicly known secret shipped in older default configs
+const InsecureJWTSecret = "bestsub-jwt-secret"
+
+var ErrInsecureJWTSecret = errors.New("jwt secret is empty or uses the insecure default, set jwt.secret or jwt.allow_insecure")
+
+// jwtSecretMutex Guards JWT.Secret, which can be rotated while requests are served
+var jwtSecretMutex sync.RWMutex
+
+// JWTSecret Returns the current signing key
+func JWTSecret(cfg *model.Config) []byte {
+	jwtSecretMutex.RLock()
+	defer jwtSecretMutex.RUnlock()
+
+	return []byte(cfg.JWT.Secret)
+}
+
+// RotateJWTSecret Replaces the signing key with a random one and persists it to the config file
+// Every token signed with the previous key fails validation afterwards. The lock is held while the file is
+// written, so concurrent rotations cannot leave the running secret different from the persisted one
+func RotateJWTSecret(cfg *model.Config) error {
+	jwtSecretMutex.Lock()
+	defer jwtSecretMutex.Unlock()
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		return err
+	}
+
+	if err := persistJWTSecret(secret); err != nil {
+		return err
+	}
+
+	cfg.JWT.Secret = secret
+
+	logger.Info("JWT secret rotated, all existing sessions have been invalidated")
+	return nil
+}
+
+// persistJWTSecret Writes only the secret back to the loaded config file,
+// so runtime overrides such as -port are not persisted
+func persistJWTSecret(secret string) error {
+	if loadedPath == "" {
+		return errors.New("config file path unknown")
+	}
+
+	stored, err := readConfig(loadedPath)
+	if err != nil {
+		return err
+	}
+	stored.JWT.Secret = secret
+
+	data, err := json.MarshalIndent(stored, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(loadedPath, data, 0644)
+}
+
+// GenerateSecret Generates a random hex encoded 256-bit secret
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// ensureJWTSecret Rejects empty or default secrets unless AllowInsecure is set
+// With AllowInsecure an empty secret is still replaced by a random one for this run,
+// since signing with an empty key would make tokens trivially forgeable
+func ensureJWTSecret(cfg *model.Config) error {
+	if cfg.JWT.Secret != "" && cfg.JWT.Secret != InsecureJWTSecret {
+		return nil
+	}
+
+	if !cfg.JWT.AllowInsecure {
+		return ErrInsecureJWTSecret
+	}
+
+	if cfg.JWT.Secret == "" {
+		secret, err := GenerateSecret()
+		if err != nil {
+			return err
+		}
+		cfg.JWT.Secret = secret
+		logger.Warn("JWT secret is empty, generated a random secret for this run, tokens will not survive a restart")
+		return nil
+	}
+
+	logger.Warn("JWT secret uses the insecure default, tokens can be forged by anyone")
+	return nil
+}
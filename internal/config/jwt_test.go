@@ -0,0 +1,65 @@
+package config
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRotateJWTSecret(t *testing.T) {
+	path := writeConfig(t, `{"jwt":{"secret":"`+testSecret+`"},"server":{"port":9999}}`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load error = %v", err)
+	}
+
+	// Runtime overrides must not be written back by the rotation
+	cfg.Server.Port = 1234
+
+	if err := RotateJWTSecret(cfg); err != nil {
+		t.Fatalf("RotateJWTSecret error = %v", err)
+	}
+
+	rotated := string(JWTSecret(cfg))
+	if rotated == testSecret || len(rotated) != 64 {
+		t.Fatalf("secret after rotation = %q, want a new random secret", rotated)
+	}
+
+	stored, err := readConfig(path)
+	if err != nil {
+		t.Fatalf("readConfig error = %v", err)
+	}
+	if stored.JWT.Secret != rotated {
+		t.Errorf("persisted secret = %q, want %q", stored.JWT.Secret, rotated)
+	}
+	if stored.Server.Port != 9999 {
+		t.Errorf("persisted port = %d, want the file's 9999", stored.Server.Port)
+	}
+}
+
+func TestRotateJWTSecretConcurrent(t *testing.T) {
+	path := writeConfig(t, `{"jwt":{"secret":"`+testSecret+`"}}`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := RotateJWTSecret(cfg); err != nil {
+				t.Errorf("RotateJWTSecret error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stored, err := readConfig(path)
+	if err != nil {
+		t.Fatalf("readConfig error = %v", err)
+	}
+	if running := string(JWTSecret(cfg)); stored.JWT.Secret != running {
+		t.Errorf("persisted secret = %q, running secret = %q, want them equal", stored.JWT.Secret, running)
+	}
+}
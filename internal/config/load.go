@@ -2,34 +2,125 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/validator"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var defaultConfig = &model.Config{
 	Server: struct {
-		Port int    `json:"port"`
-		Host string `json:"host"`
+		Port            int    `json:"port"`
+		Host            string `json:"host"`
+		MaintenanceMode bool   `json:"maintenance_mode"`
+		APIOnly         bool   `json:"api_only"`
+		// AdminAllowedCIDRs IPs or CIDRs allowed to reach admin endpoints, empty allows all
+		AdminAllowedCIDRs []string `json:"admin_allowed_cidrs"`
+		// RedirectTrailingSlash Redirects /path/ to /path (and back) when only the other form is routed
+		RedirectTrailingSlash bool `json:"redirect_trailing_slash"`
+		// CaseInsensitiveRoutes Redirects paths that only differ in case or extra slashes to the routed path
+		CaseInsensitiveRoutes bool `json:"case_insensitive_routes"`
+		// RouteTimeouts Handler timeout in seconds per route group path, e.g. {"/api/sub": 60}, requests over it get 504
+		RouteTimeouts map[string]int `json:"route_timeouts"`
+		// MaxConcurrentPerIP Requests a single client IP may have in flight, 0 disables the limit
+		MaxConcurrentPerIP int `json:"max_concurrent_per_ip"`
+		// MaxRequestTimeoutSeconds Upper bound for the X-Timeout-Seconds request header, 0 ignores the header
+		MaxRequestTimeoutSeconds int `json:"max_request_timeout_seconds"`
+		// LogBodies Logs request and response bodies (size-capped, secrets redacted) while the log level is debug
+		LogBodies bool `json:"log_bodies"`
+		// TLS Serves HTTPS when both files are set, certificates are reloaded when the files change
+		TLS struct {
+			CertFile string `json:"cert_file"`
+			KeyFile  string `json:"key_file"`
+			// AutoCert Obtains certificates from Let's Encrypt for Domains, port 80 is bound for the HTTP-01 challenge
+			AutoCert struct {
+				Domains  []string `json:"domains"`
+				CacheDir string   `json:"cache_dir"`
+				Email    string   `json:"email"`
+			} `json:"auto_cert"`
+		} `json:"tls"`
 	}{
-		Port: 8080,
-		Host: "0.0.0.0",
+		Port:                     8080,
+		Host:                     "0.0.0.0",
+		RedirectTrailingSlash:    true,
+		MaxConcurrentPerIP:       32,
+		MaxRequestTimeoutSeconds: 120,
 	},
 	Database: struct {
-		Path string `json:"path"`
+		Path        string `json:"path"`
+		AutoMigrate bool   `json:"auto_migrate"`
+		// MaintenanceIntervalHours Interval of audit log pruning and vacuuming, 0 disables maintenance
+		MaintenanceIntervalHours int `json:"maintenance_interval_hours"`
+		// AuditRetentionDays Audit log entries older than this are deleted during maintenance, 0 keeps them forever
+		AuditRetentionDays int `json:"audit_retention_days"`
 	}{
-		Path: "data/bestsub.db",
+		Path:                     "data/bestsub.db",
+		AutoMigrate:              true,
+		MaintenanceIntervalHours: 24,
+		AuditRetentionDays:       90,
 	},
 	JWT: struct {
-		Secret    string `json:"secret"`
-		ExpiresIn int    `json:"expires_in"`
+		Secret        string `json:"secret"`
+		ExpiresIn     int    `json:"expires_in"`
+		AllowInsecure bool   `json:"allow_insecure"`
 	}{
-		Secret:    "bestsub-jwt-secret",
+		Secret:    InsecureJWTSecret,
 		ExpiresIn: 3600,
 	},
+	Password: struct {
+		// Algorithm Hash used for new passwords, bcrypt or argon2id. Hashes of the other algorithm still verify
+		// and are rehashed on the next successful login
+		Algorithm string `json:"algorithm"`
+		// BcryptCost Work factor of new bcrypt hashes, 0 uses bcrypt's default. Hashes with another cost are
+		// rehashed on the next successful login
+		BcryptCost int `json:"bcrypt_cost"`
+	}{
+		Algorithm:  model.PasswordHashBcrypt,
+		BcryptCost: bcrypt.DefaultCost,
+	},
+	Fetcher: struct {
+		MaxConcurrentPerHost int `json:"max_concurrent_per_host"`
+		// MaxRedirects Redirect chains are stopped once they reach this many requests, 0 disables redirects
+		MaxRedirects int `json:"max_redirects"`
+		// TimeoutSeconds Overall limit for a fetch including reading the body
+		TimeoutSeconds int `json:"timeout_seconds"`
+		// DialTimeoutSeconds Limit for establishing the TCP connection
+		DialTimeoutSeconds int `json:"dial_timeout_seconds"`
+		// TLSHandshakeTimeoutSeconds Limit for the TLS handshake
+		TLSHandshakeTimeoutSeconds int `json:"tls_handshake_timeout_seconds"`
+		// KeepPreviousOnEmpty Rejects empty fetch results so the previously stored content is kept
+		KeepPreviousOnEmpty bool `json:"keep_previous_on_empty"`
+	}{
+		MaxConcurrentPerHost:       2,
+		MaxRedirects:               10,
+		TimeoutSeconds:             30,
+		DialTimeoutSeconds:         10,
+		TLSHandshakeTimeoutSeconds: 10,
+		KeepPreviousOnEmpty:        true,
+	},
+	Scheduler: struct {
+		DefaultCron string `json:"default_cron"`
+		// ValidateOnStartup Checks the cron of every stored sub at startup and reports invalid ones
+		ValidateOnStartup bool `json:"validate_on_startup"`
+	}{
+		DefaultCron:       "0 */1 * * *",
+		ValidateOnStartup: true,
+	},
+	ContentStore: struct {
+		// MaxAgeSeconds Cached content of subs not fetched for this long is evicted, 0 disables the sweep
+		MaxAgeSeconds int `json:"max_age_seconds"`
+	}{
+		MaxAgeSeconds: 7 * 24 * 3600,
+	},
 }
 
+// loadedPath Path of the config file passed to Load
+var loadedPath string
+
 func Load(path string) (*model.Config, error) {
 	configDir := filepath.Dir(path)
 	if err := os.MkdirAll(configDir, 0755); err != nil {
@@ -52,11 +143,61 @@ func Load(path string) (*model.Config, error) {
 		}
 	}
 
+	if err := ensureJWTSecret(cfg); err != nil {
+		return nil, err
+	}
+
+	if err := validator.ValidateCron(cfg.Scheduler.DefaultCron); err != nil {
+		return nil, fmt.Errorf("invalid scheduler.default_cron: %w", err)
+	}
+
+	switch cfg.Password.Algorithm {
+	case model.PasswordHashBcrypt, model.PasswordHashArgon2id:
+	default:
+		return nil, fmt.Errorf("invalid password.algorithm %q, must be %s or %s",
+			cfg.Password.Algorithm, model.PasswordHashBcrypt, model.PasswordHashArgon2id)
+	}
+
+	if cost := cfg.Password.BcryptCost; cost != 0 && (cost < bcrypt.MinCost || cost > bcrypt.MaxCost) {
+		return nil, fmt.Errorf("invalid password.bcrypt_cost %d, must be between %d and %d", cost, bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	for _, entry := range cfg.Server.AdminAllowedCIDRs {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		if _, err := validator.ParseIPOrCIDR(entry); err != nil {
+			return nil, fmt.Errorf("invalid server.admin_allowed_cidrs entry %q: %w", entry, err)
+		}
+	}
+
+	if cfg.Fetcher.MaxRedirects < 0 {
+		return nil, fmt.Errorf("invalid fetcher.max_redirects %d, must not be negative", cfg.Fetcher.MaxRedirects)
+	}
+
+	if (cfg.Server.TLS.CertFile == "") != (cfg.Server.TLS.KeyFile == "") {
+		return nil, fmt.Errorf("server.tls.cert_file and server.tls.key_file must be set together")
+	}
+
+	if cfg.Server.TLS.CertFile != "" && len(cfg.Server.TLS.AutoCert.Domains) > 0 {
+		return nil, fmt.Errorf("server.tls.cert_file and server.tls.auto_cert cannot be used together")
+	}
+
+	loadedPath = path
+
 	return cfg, nil
 }
 
 func createDefaultConfig(path string) (*model.Config, error) {
-	data, err := json.MarshalIndent(defaultConfig, "", "    ")
+	cfg := *defaultConfig
+
+	secret, err := GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	cfg.JWT.Secret = secret
+
+	data, err := json.MarshalIndent(&cfg, "", "    ")
 	if err != nil {
 		return nil, err
 	}
@@ -65,7 +206,7 @@ func createDefaultConfig(path string) (*model.Config, error) {
 		return nil, err
 	}
 
-	return defaultConfig, nil
+	return &cfg, nil
 }
 
 func readConfig(path string) (*model.Config, error) {
@@ -74,7 +215,8 @@ func readConfig(path string) (*model.Config, error) {
 		return nil, err
 	}
 
-	var cfg model.Config
+	// Start from the defaults so options missing from older config files keep their default values
+	cfg := *defaultConfig
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil, err
 	}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bestruirui/bestsub/internal/validator"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// writeConfig Writes content to a config file in a temporary directory and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadRejectsInsecureJWTSecret(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty", `{"jwt":{"secret":""}}`},
+		{"missing", `{}`},
+		{"default", `{"jwt":{"secret":"` + InsecureJWTSecret + `"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if !errors.Is(err, ErrInsecureJWTSecret) {
+				t.Errorf("Load error = %v, want ErrInsecureJWTSecret", err)
+			}
+		})
+	}
+}
+
+func TestLoadAllowInsecureJWTSecret(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `{"jwt":{"secret":"","allow_insecure":true}}`))
+	if err != nil {
+		t.Fatalf("Load error = %v", err)
+	}
+	if len(cfg.JWT.Secret) != 64 {
+		t.Errorf("secret = %q, want a random 64 character secret for an empty one", cfg.JWT.Secret)
+	}
+
+	cfg, err = Load(writeConfig(t, `{"jwt":{"secret":"`+InsecureJWTSecret+`","allow_insecure":true}}`))
+	if err != nil {
+		t.Fatalf("Load error = %v", err)
+	}
+	if cfg.JWT.Secret != InsecureJWTSecret {
+		t.Errorf("secret = %q, want the configured default to be kept", cfg.JWT.Secret)
+	}
+}
+
+func TestLoadCreatesConfigWithRandomSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load error = %v", err)
+	}
+	if cfg.JWT.Secret == "" || cfg.JWT.Secret == InsecureJWTSecret {
+		t.Errorf("secret = %q, want a random secret", cfg.JWT.Secret)
+	}
+
+	// The generated secret is persisted, so a restart keeps sessions valid
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("reload error = %v", err)
+	}
+	if reloaded.JWT.Secret != cfg.JWT.Secret {
+		t.Errorf("reloaded secret = %q, want %q", reloaded.JWT.Secret, cfg.JWT.Secret)
+	}
+}
+
+func TestLoadMaxRedirects(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `{"jwt":{"secret":"`+testSecret+`"}}`))
+	if err != nil {
+		t.Fatalf("Load error = %v", err)
+	}
+	if cfg.Fetcher.MaxRedirects != 10 {
+		t.Errorf("default max_redirects = %d, want 10", cfg.Fetcher.MaxRedirects)
+	}
+
+	cfg, err = Load(writeConfig(t, `{"jwt":{"secret":"`+testSecret+`"},"fetcher":{"max_redirects":0}}`))
+	if err != nil {
+		t.Fatalf("Load error = %v", err)
+	}
+	if cfg.Fetcher.MaxRedirects != 0 {
+		t.Errorf("max_redirects = %d, want 0 to be kept", cfg.Fetcher.MaxRedirects)
+	}
+
+	if _, err := Load(writeConfig(t, `{"jwt":{"secret":"`+testSecret+`"},"fetcher":{"max_redirects":-1}}`)); err == nil {
+		t.Error("Load accepted a negative max_redirects")
+	}
+}
+
+func TestLoadAdminAllowedCIDRs(t *testing.T) {
+	tests := []struct {
+		cidrs   string
+		wantErr bool
+	}{
+		{`["10.0.0.0/8", "192.168.1.5", "::1"]`, false},
+		{`["10.0.0.0/8", "10.0.0.0/33"]`, true},
+		{`["localhost"]`, true},
+	}
+
+	for _, tt := range tests {
+		path := writeConfig(t, `{"jwt":{"secret":"`+testSecret+`"},"server":{"admin_allowed_cidrs":`+tt.cidrs+`}}`)
+		_, err := Load(path)
+		if tt.wantErr && !errors.Is(err, validator.ErrInvalidCIDR) {
+			t.Errorf("Load(%s) error = %v, want ErrInvalidCIDR", tt.cidrs, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("Load(%s) error = %v", tt.cidrs, err)
+		}
+	}
+}
+
+func TestLoadBcryptCost(t *testing.T) {
+	tests := []struct {
+		cost    string
+		want    int
+		wantErr bool
+	}{
+		{`12`, 12, false},
+		{`0`, 0, false},
+		{`3`, 0, true},
+		{`32`, 0, true},
+	}
+
+	for _, tt := range tests {
+		cfg, err := Load(writeConfig(t, `{"jwt":{"secret":"`+testSecret+`"},"password":{"algorithm":"bcrypt","bcrypt_cost":`+tt.cost+`}}`))
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Load(bcrypt_cost %s) succeeded, want an error", tt.cost)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Load(bcrypt_cost %s) error = %v", tt.cost, err)
+			continue
+		}
+		if cfg.Password.BcryptCost != tt.want {
+			t.Errorf("bcrypt cost = %d, want %d", cfg.Password.BcryptCost, tt.want)
+		}
+	}
+}
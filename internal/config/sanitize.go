@@ -0,0 +1,25 @@
+package config
+
+import "github.com/bestruirui/bestsub/internal/model"
+
+// RedactedValue Placeholder for secrets in sanitized configs, empty secrets stay empty
+const RedactedValue = "***"
+
+// Sanitized Returns a copy of the running config safe to show to operators, secrets are masked
+// The copy shares slices and maps with cfg and must not be modified
+func Sanitized(cfg *model.Config) model.Config {
+	jwtSecretMutex.RLock()
+	sanitized := *cfg
+	jwtSecretMutex.RUnlock()
+
+	sanitized.JWT.Secret = redact(sanitized.JWT.Secret)
+	return sanitized
+}
+
+// redact Masks a non-empty secret
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return RedactedValue
+}
@@ -5,11 +5,12 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/bestruirui/bestsub/internal/logger"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -30,6 +31,10 @@ type Config struct {
 	MaxOpenConns int
 	// Maximum lifetime of connections
 	ConnMaxLifetime time.Duration
+	// Apply pending migrations on startup
+	AutoMigrate bool
+	// Cost of the initial admin password hash, 0 uses bcrypt's default
+	BcryptCost int
 }
 
 // DefaultConfig Returns default configuration
@@ -39,6 +44,7 @@ func DefaultConfig(path string) Config {
 		MaxIdleConns:    10,
 		MaxOpenConns:    100,
 		ConnMaxLifetime: time.Hour,
+		AutoMigrate:     true,
 	}
 }
 
@@ -62,7 +68,8 @@ func InitDatabaseWithConfig(config Config) error {
 func setupDatabase(config Config) (*sql.DB, error) {
 	logger.Info("Opening database connection to %s", config.Path)
 
-	db, err := sql.Open("sqlite3", config.Path+"?_loc=auto&_journal=WAL&_timeout=5000")
+	// auto_vacuum only takes effect for new databases, existing ones are converted by the first Vacuum
+	db, err := sql.Open("sqlite3", config.Path+"?_loc=auto&_journal=WAL&_timeout=5000&_auto_vacuum=incremental")
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -78,22 +85,52 @@ func setupDatabase(config Config) (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	fresh, err := isNewDatabase(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect schema: %w", err)
+	}
+
 	if err := createSchema(db); err != nil {
 		return nil, fmt.Errorf("failed to create schema: %w", err)
 	}
 
-	if err := createInitialAdminUser(db); err != nil {
+	if err := createInitialAdminUser(db, config.BcryptCost); err != nil {
 		return nil, fmt.Errorf("failed to create admin user: %w", err)
 	}
 
-	if err := RunMigrations(db); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	// createSchema builds a new database at the latest schema, so its migrations are only recorded
+	if config.AutoMigrate || fresh {
+		if err := RunMigrations(db); err != nil {
+			return nil, fmt.Errorf("failed to run migrations: %w", err)
+		}
+	} else {
+		pending, err := PendingMigrations(db)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check pending migrations: %w", err)
+		}
+		for _, migration := range pending {
+			logger.Warn("Pending migration %d: %s", migration.Version, migration.Description)
+		}
+		if len(pending) > 0 {
+			logger.Warn("Automatic migration is disabled, the server reports not ready until %d pending migration(s) are applied "+
+				"with -migrate or POST /api/system/migrate", len(pending))
+		}
 	}
 
 	logger.Info("Database initialized successfully")
 	return db, nil
 }
 
+// isNewDatabase Reports whether the database has no subs table yet
+func isNewDatabase(db *sql.DB) (bool, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'subs'").Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
 // createSchema Creates database table structure
 func createSchema(db *sql.DB) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -129,18 +166,36 @@ func createSchema(db *sql.DB) error {
 			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
 			total_nodes INTEGER DEFAULT 0,
-			alive_nodes INTEGER DEFAULT 0
+			alive_nodes INTEGER DEFAULT 0,
+			sort_order INTEGER DEFAULT 0,
+			url_vars TEXT DEFAULT '',
+			fetch_timeout_seconds INTEGER DEFAULT 0,
+			content_size INTEGER DEFAULT 0,
+			remark TEXT DEFAULT '',
+			success_pattern TEXT DEFAULT '',
+			pinned INTEGER DEFAULT 0,
+			fetch_method TEXT DEFAULT 'GET',
+			fetch_body TEXT DEFAULT '',
+			pinned_content TEXT
 		)
 	`)
 	if err != nil {
 		return err
 	}
 
+	if err := createAuditLogTable(tx); err != nil {
+		return err
+	}
+
+	if err := createTokenBlacklistTable(tx); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
 // createInitialAdminUser Creates initial admin account
-func createInitialAdminUser(db *sql.DB) error {
+func createInitialAdminUser(db *sql.DB, bcryptCost int) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -157,7 +212,10 @@ func createInitialAdminUser(db *sql.DB) error {
 		}
 		defer tx.Rollback()
 
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte("admin"), bcrypt.DefaultCost)
+		if bcryptCost == 0 {
+			bcryptCost = bcrypt.DefaultCost
+		}
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte("admin"), bcryptCost)
 		if err != nil {
 			return err
 		}
@@ -181,8 +239,37 @@ func createInitialAdminUser(db *sql.DB) error {
 	return nil
 }
 
+const (
+	// maxBusyRetries Number of extra attempts WithTransaction makes when SQLite reports the database busy
+	maxBusyRetries = 3
+	// busyRetryBackoff Base delay between busy retries, doubled on every attempt
+	busyRetryBackoff = 50 * time.Millisecond
+)
+
 // WithTransaction Executes a function within a transaction
+// The whole transaction is retried with backoff when SQLite reports the database busy or locked,
+// so fn must not have side effects outside the transaction
 func WithTransaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	backoff := busyRetryBackoff
+	for attempt := 0; ; attempt++ {
+		err := runTransaction(ctx, fn)
+		if err == nil || attempt >= maxBusyRetries || !isBusyError(err) {
+			return err
+		}
+
+		logger.Debug("Database busy, retrying transaction (attempt %d): %v", attempt+1, err)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// runTransaction Executes a function within a single transaction attempt
+func runTransaction(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
 	tx, err := DB.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -202,13 +289,23 @@ func WithTransaction(ctx context.Context, fn func(tx *sql.Tx) error) error {
 		return err
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
 }
 
+// isBusyError Reports whether err is SQLite's SQLITE_BUSY or SQLITE_LOCKED
+func isBusyError(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	// Some call sites wrap the driver error with %v, fall back to the message
+	return strings.Contains(err.Error(), "database is locked")
+}
+
 func Close() error {
 	if DB != nil {
 		logger.Info("Closing database connection")
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestInitialAdminBcryptCost(t *testing.T) {
+	config := DefaultConfig(filepath.Join(t.TempDir(), "cost.db"))
+	config.BcryptCost = bcrypt.MinCost + 1
+
+	db, err := setupDatabase(config)
+	if err != nil {
+		t.Fatalf("setupDatabase error = %v", err)
+	}
+	defer db.Close()
+
+	var hashed string
+	if err := db.QueryRow("SELECT password FROM users WHERE username = 'admin'").Scan(&hashed); err != nil {
+		t.Fatalf("failed to read admin password: %v", err)
+	}
+	cost, err := bcrypt.Cost([]byte(hashed))
+	if err != nil {
+		t.Fatalf("bcrypt.Cost error = %v", err)
+	}
+	if cost != config.BcryptCost {
+		t.Errorf("admin hash cost = %d, want %d", cost, config.BcryptCost)
+	}
+}
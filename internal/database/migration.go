@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -28,55 +29,169 @@ var migrations = []Migration{
 		Description: "添加节点统计字段到subs表",
 		Execute:     addNodesStatsColumns,
 	},
+	{
+		Version:     3,
+		Description: "添加排序字段到subs表",
+		Execute:     addSortOrderColumn,
+	},
+	{
+		Version:     4,
+		Description: "添加URL模板变量字段到subs表",
+		Execute:     addURLVarsColumn,
+	},
+	{
+		Version:     5,
+		Description: "添加审计日志表",
+		Execute:     createAuditLogTable,
+	},
+	{
+		Version:     6,
+		Description: "添加订阅获取超时字段到subs表",
+		Execute:     addFetchTimeoutColumn,
+	},
+	{
+		Version:     7,
+		Description: "添加订阅内容大小字段到subs表",
+		Execute:     addContentSizeColumn,
+	},
+	{
+		Version:     8,
+		Description: "添加JWT令牌黑名单表",
+		Execute:     createTokenBlacklistTable,
+	},
+	{
+		Version:     9,
+		Description: "添加订阅备注字段到subs表",
+		Execute:     addRemarkColumn,
+	},
+	{
+		Version:     10,
+		Description: "添加订阅成功匹配规则字段到subs表",
+		Execute:     addSuccessPatternColumn,
+	},
+	{
+		Version:     11,
+		Description: "添加订阅固定内容字段到subs表",
+		Execute:     addPinnedColumn,
+	},
+	{
+		Version:     12,
+		Description: "添加订阅请求方法和请求体字段到subs表",
+		Execute:     addFetchMethodColumns,
+	},
+	{
+		Version:     13,
+		Description: "添加订阅固定内容副本字段到subs表",
+		Execute:     addPinnedContentColumn,
+	},
 }
 
+// ErrInvalidMigrationTarget Target version is not newer than the current version or does not exist
+var ErrInvalidMigrationTarget = errors.New("invalid migration target version")
+
 func RunMigrations(db *sql.DB) error {
 	logger.Info("Running database migrations...")
 
+	if _, err := migrate(db, LatestVersion(), false); err != nil {
+		return err
+	}
+
+	logger.Info("Database migrations completed successfully")
+	return nil
+}
+
+// MigrateTo Applies pending migrations up to and including target, returns the applied versions
+// The target must be newer than the current database version
+func MigrateTo(db *sql.DB, target int) ([]int, error) {
+	if target > LatestVersion() {
+		return nil, fmt.Errorf("%w: latest version is %d", ErrInvalidMigrationTarget, LatestVersion())
+	}
+	return migrate(db, target, true)
+}
+
+// LatestVersion Returns the version of the newest known migration
+func LatestVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// migrate Applies migrations newer than the current version up to target in a single transaction
+// With requireNewer a target at or below the current version is rejected instead of being a no-op
+func migrate(db *sql.DB, target int, requireNewer bool) ([]int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
 	if err := ensureMigrationTableExists(tx); err != nil {
-		return fmt.Errorf("failed to ensure migration table exists: %w", err)
+		return nil, fmt.Errorf("failed to ensure migration table exists: %w", err)
 	}
 
 	currentVersion, err := getCurrentVersion(tx)
 	if err != nil {
-		return fmt.Errorf("failed to get current migration version: %w", err)
+		return nil, fmt.Errorf("failed to get current migration version: %w", err)
 	}
 
 	logger.Info("Current database version: %d", currentVersion)
 
+	if requireNewer && target <= currentVersion {
+		return nil, fmt.Errorf("%w: current version is %d", ErrInvalidMigrationTarget, currentVersion)
+	}
+
+	applied := []int{}
 	for _, migration := range migrations {
-		if migration.Version <= currentVersion {
+		if migration.Version <= currentVersion || migration.Version > target {
 			continue
 		}
 
 		logger.Info("Applying migration %d: %s", migration.Version, migration.Description)
 
 		if err := migration.Execute(tx); err != nil {
-			return fmt.Errorf("failed to apply migration %d: %w", migration.Version, err)
+			return nil, fmt.Errorf("failed to apply migration %d: %w", migration.Version, err)
 		}
 
 		if err := updateVersionRecord(tx, migration.Version, migration.Description); err != nil {
-			return fmt.Errorf("failed to update version record: %w", err)
+			return nil, fmt.Errorf("failed to update version record: %w", err)
 		}
 
 		logger.Info("Successfully applied migration %d", migration.Version)
+		applied = append(applied, migration.Version)
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit migrations: %w", err)
+		return nil, fmt.Errorf("failed to commit migrations: %w", err)
 	}
 
-	logger.Info("Database migrations completed successfully")
-	return nil
+	return applied, nil
+}
+
+// PendingMigrations Returns migrations newer than the current database version
+func PendingMigrations(db *sql.DB) ([]Migration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	currentVersion, err := getCurrentVersion(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current migration version: %w", err)
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if migration.Version > currentVersion {
+			pending = append(pending, migration)
+		}
+	}
+
+	return pending, nil
 }
 
 // ensureMigrationTableExists 确保迁移表存在
@@ -170,6 +285,129 @@ func addNodesStatsColumns(tx *sql.Tx) error {
 	return nil
 }
 
+// addSortOrderColumn 迁移：添加排序字段到subs表，并按ID初始化已有订阅的顺序
+func addSortOrderColumn(tx *sql.Tx) error {
+	var count int
+	err := tx.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('subs') 
+		WHERE name = 'sort_order'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check if sort_order column exists: %w", err)
+	}
+
+	if count == 0 {
+		_, err = tx.Exec("ALTER TABLE subs ADD COLUMN sort_order INTEGER DEFAULT 0")
+		if err != nil {
+			return fmt.Errorf("failed to add sort_order column: %w", err)
+		}
+	}
+
+	_, err = tx.Exec("UPDATE subs SET sort_order = id WHERE sort_order = 0")
+	if err != nil {
+		return fmt.Errorf("failed to initialize sort_order: %w", err)
+	}
+
+	return nil
+}
+
+// addURLVarsColumn 迁移：添加URL模板变量字段到subs表
+func addURLVarsColumn(tx *sql.Tx) error {
+	return addColumnIfNotExists(tx, "subs", "url_vars", "TEXT DEFAULT ''")
+}
+
+// createAuditLogTable 迁移：添加审计日志表
+func createAuditLogTable(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS audit_log (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			action TEXT NOT NULL,
+			target TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create audit_log table: %w", err)
+	}
+	return nil
+}
+
+// addFetchTimeoutColumn 迁移：添加订阅获取超时字段到subs表
+func addFetchTimeoutColumn(tx *sql.Tx) error {
+	return addColumnIfNotExists(tx, "subs", "fetch_timeout_seconds", "INTEGER DEFAULT 0")
+}
+
+// addContentSizeColumn 迁移：添加订阅内容大小字段到subs表
+func addContentSizeColumn(tx *sql.Tx) error {
+	return addColumnIfNotExists(tx, "subs", "content_size", "INTEGER DEFAULT 0")
+}
+
+// createTokenBlacklistTable 迁移：添加JWT令牌黑名单表
+func createTokenBlacklistTable(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS token_blacklist (
+			jti TEXT PRIMARY KEY,
+			expires_at INTEGER NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create token_blacklist table: %w", err)
+	}
+	return nil
+}
+
+// addRemarkColumn 迁移：添加订阅备注字段到subs表
+func addRemarkColumn(tx *sql.Tx) error {
+	return addColumnIfNotExists(tx, "subs", "remark", "TEXT DEFAULT ''")
+}
+
+// addSuccessPatternColumn 迁移：添加订阅成功匹配规则字段到subs表
+func addSuccessPatternColumn(tx *sql.Tx) error {
+	return addColumnIfNotExists(tx, "subs", "success_pattern", "TEXT DEFAULT ''")
+}
+
+// addPinnedColumn 迁移：添加订阅固定内容字段到subs表
+func addPinnedColumn(tx *sql.Tx) error {
+	return addColumnIfNotExists(tx, "subs", "pinned", "INTEGER DEFAULT 0")
+}
+
+// addFetchMethodColumns 迁移：添加订阅请求方法和请求体字段到subs表
+func addFetchMethodColumns(tx *sql.Tx) error {
+	if err := addColumnIfNotExists(tx, "subs", "fetch_method", "TEXT DEFAULT 'GET'"); err != nil {
+		return err
+	}
+	return addColumnIfNotExists(tx, "subs", "fetch_body", "TEXT DEFAULT ''")
+}
+
+// addPinnedContentColumn 迁移：添加订阅固定内容副本字段到subs表
+func addPinnedContentColumn(tx *sql.Tx) error {
+	return addColumnIfNotExists(tx, "subs", "pinned_content", "TEXT")
+}
+
+// addColumnIfNotExists 当字段不存在时为表添加字段
+func addColumnIfNotExists(tx *sql.Tx, table, column, definition string) error {
+	var count int
+	err := tx.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info(?) 
+		WHERE name = ?
+	`, table, column).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to check if %s column exists: %w", column, err)
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	if err != nil {
+		return fmt.Errorf("failed to add %s column: %w", column, err)
+	}
+
+	return nil
+}
+
 func addNewColumnMigration(tx *sql.Tx) error {
 	var count int
 	err := tx.QueryRow(`
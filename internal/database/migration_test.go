@@ -0,0 +1,198 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// openTestDB Sets up the database at path the way the server does at startup
+func openTestDB(t *testing.T, path string, autoMigrate bool) *sql.DB {
+	t.Helper()
+
+	config := DefaultConfig(path)
+	config.AutoMigrate = autoMigrate
+	config.BcryptCost = bcrypt.MinCost
+
+	db, err := setupDatabase(config)
+	if err != nil {
+		t.Fatalf("setupDatabase error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// newLegacyDB Creates a database file with the schema of the first release, at migration version 2
+func newLegacyDB(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	statements := []string{
+		`CREATE TABLE users (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			username TEXT UNIQUE NOT NULL,
+			password TEXT NOT NULL,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE subs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			url TEXT NOT NULL,
+			last_check DATETIME,
+			last_fetch DATETIME,
+			cron TEXT DEFAULT '0 */1 * * *',
+			auto_update INTEGER DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			total_nodes INTEGER DEFAULT 0,
+			alive_nodes INTEGER DEFAULT 0
+		)`,
+		`CREATE TABLE migrations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			version INTEGER NOT NULL,
+			description TEXT NOT NULL,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`INSERT INTO migrations (version, description) VALUES (1, 'v1'), (2, 'v2')`,
+		`INSERT INTO subs (url) VALUES ('http://a.example/sub'), ('http://b.example/sub')`,
+	}
+	for _, stmt := range statements {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("failed to create legacy schema: %v", err)
+		}
+	}
+	return path
+}
+
+// columnExists Reports whether table has column
+func columnExists(t *testing.T, db *sql.DB, table, column string) bool {
+	t.Helper()
+
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	if err != nil {
+		t.Fatalf("failed to inspect %s: %v", table, err)
+	}
+	return count > 0
+}
+
+// currentVersion Returns the migration version recorded in db
+func currentVersion(t *testing.T, db *sql.DB) int {
+	t.Helper()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	version, err := getCurrentVersion(tx)
+	if err != nil {
+		t.Fatalf("getCurrentVersion error = %v", err)
+	}
+	return version
+}
+
+func TestAutoMigrateDisabled(t *testing.T) {
+	path := newLegacyDB(t)
+	db := openTestDB(t, path, false)
+
+	if columnExists(t, db, "subs", "sort_order") || columnExists(t, db, "subs", "remark") {
+		t.Error("migration columns were added although auto_migrate is disabled")
+	}
+	if v := currentVersion(t, db); v != 2 {
+		t.Errorf("version = %d, want 2", v)
+	}
+
+	pending, err := PendingMigrations(db)
+	if err != nil {
+		t.Fatalf("PendingMigrations error = %v", err)
+	}
+	if len(pending) != LatestVersion()-2 || pending[0].Version != 3 {
+		t.Errorf("pending = %d migrations starting at %d, want versions 3..%d", len(pending), pending[0].Version, LatestVersion())
+	}
+}
+
+func TestAutoMigrateEnabled(t *testing.T) {
+	path := newLegacyDB(t)
+	db := openTestDB(t, path, true)
+
+	for _, column := range []string{"sort_order", "url_vars", "remark", "pinned", "fetch_method"} {
+		if !columnExists(t, db, "subs", column) {
+			t.Errorf("column %s missing after migration", column)
+		}
+	}
+	if v := currentVersion(t, db); v != LatestVersion() {
+		t.Errorf("version = %d, want %d", v, LatestVersion())
+	}
+
+	// Existing subs get their ID as initial position
+	var orders []int
+	rows, err := db.Query("SELECT sort_order FROM subs ORDER BY id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var order int
+		rows.Scan(&order)
+		orders = append(orders, order)
+	}
+	if !slices.Equal(orders, []int{1, 2}) {
+		t.Errorf("sort orders = %v, want [1 2]", orders)
+	}
+}
+
+func TestNewDatabaseIsStampedWithoutAutoMigrate(t *testing.T) {
+	db := openTestDB(t, filepath.Join(t.TempDir(), "new.db"), false)
+
+	pending, err := PendingMigrations(db)
+	if err != nil {
+		t.Fatalf("PendingMigrations error = %v", err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("new database has %d pending migrations, want none", len(pending))
+	}
+	if v := currentVersion(t, db); v != LatestVersion() {
+		t.Errorf("version = %d, want %d", v, LatestVersion())
+	}
+}
+
+func TestMigrateTo(t *testing.T) {
+	db := openTestDB(t, newLegacyDB(t), false)
+
+	applied, err := MigrateTo(db, 3)
+	if err != nil {
+		t.Fatalf("MigrateTo(3) error = %v", err)
+	}
+	if !slices.Equal(applied, []int{3}) {
+		t.Errorf("applied = %v, want [3]", applied)
+	}
+	if !columnExists(t, db, "subs", "sort_order") || columnExists(t, db, "subs", "url_vars") {
+		t.Error("MigrateTo(3) did not stop at version 3")
+	}
+
+	pending, err := PendingMigrations(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) == 0 || pending[0].Version != 4 {
+		t.Errorf("pending = %v, want migrations from version 4", pending)
+	}
+
+	for _, target := range []int{2, 3, LatestVersion() + 1} {
+		if _, err := MigrateTo(db, target); !errors.Is(err, ErrInvalidMigrationTarget) {
+			t.Errorf("MigrateTo(%d) error = %v, want ErrInvalidMigrationTarget", target, err)
+		}
+	}
+}
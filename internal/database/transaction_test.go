@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// useBusyTestDB Points DB at a new database without a busy timeout, so lock contention fails immediately
+func useBusyTestDB(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "busy.db")
+	db, err := sql.Open("sqlite3", path+"?_timeout=0")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE counter (n INTEGER NOT NULL)`); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+
+	prev := DB
+	DB = db
+	t.Cleanup(func() {
+		DB = prev
+		db.Close()
+	})
+	return path
+}
+
+// holdWriteLock Takes the write lock of the database at path until release is called
+func holdWriteLock(t *testing.T, path string) (release func()) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", path+"?_timeout=0")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("failed to get connection: %v", err)
+	}
+	if _, err := conn.ExecContext(context.Background(), "BEGIN IMMEDIATE"); err != nil {
+		t.Fatalf("failed to take write lock: %v", err)
+	}
+
+	return func() {
+		conn.ExecContext(context.Background(), "COMMIT")
+		conn.Close()
+		db.Close()
+	}
+}
+
+func TestWithTransactionRetriesWhileBusy(t *testing.T) {
+	path := useBusyTestDB(t)
+	release := holdWriteLock(t, path)
+
+	// Released after the first attempt failed but well before the retries run out
+	go func() {
+		time.Sleep(busyRetryBackoff)
+		release()
+	}()
+
+	attempts := 0
+	err := WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		attempts++
+		_, err := tx.Exec(`INSERT INTO counter (n) VALUES (1)`)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("WithTransaction error = %v, want success once the lock is released", err)
+	}
+	if attempts < 2 {
+		t.Errorf("attempts = %d, want a retry after the busy error", attempts)
+	}
+
+	var rows int
+	if err := DB.QueryRow(`SELECT COUNT(*) FROM counter`).Scan(&rows); err != nil {
+		t.Fatalf("failed to count rows: %v", err)
+	}
+	if rows != 1 {
+		t.Errorf("rows = %d, want exactly 1 after retries", rows)
+	}
+}
+
+func TestWithTransactionGivesUpWhileBusy(t *testing.T) {
+	path := useBusyTestDB(t)
+	release := holdWriteLock(t, path)
+	defer release()
+
+	attempts := 0
+	err := WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		attempts++
+		_, err := tx.Exec(`INSERT INTO counter (n) VALUES (1)`)
+		return err
+	})
+	if !isBusyError(err) {
+		t.Fatalf("WithTransaction error = %v, want a busy error", err)
+	}
+	if attempts != maxBusyRetries+1 {
+		t.Errorf("attempts = %d, want %d", attempts, maxBusyRetries+1)
+	}
+}
+
+func TestWithTransactionDoesNotRetryOtherErrors(t *testing.T) {
+	useBusyTestDB(t)
+
+	errFailed := errors.New("failed")
+	attempts := 0
+	err := WithTransaction(context.Background(), func(tx *sql.Tx) error {
+		attempts++
+		return errFailed
+	})
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("WithTransaction error = %v, want %v", err, errFailed)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
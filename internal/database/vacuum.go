@@ -0,0 +1,63 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// autoVacuumIncremental Value of PRAGMA auto_vacuum for INCREMENTAL mode
+const autoVacuumIncremental = 2
+
+// ErrVacuumInTransaction VACUUM was requested on a connection with an open transaction
+var ErrVacuumInTransaction = errors.New("cannot vacuum inside a transaction")
+
+// Vacuum Reclaims free pages of the database file
+// Databases created with auto_vacuum=INCREMENTAL only release their free pages, older databases are
+// rebuilt once with VACUUM which also switches them to incremental mode (set on every connection).
+// It takes a dedicated connection and refuses to run if that connection is inside a transaction.
+func Vacuum(ctx context.Context, db *sql.DB) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn any) error {
+		if c, ok := driverConn.(*sqlite3.SQLiteConn); ok && !c.AutoCommit() {
+			return ErrVacuumInTransaction
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var mode int
+	if err := conn.QueryRowContext(ctx, "PRAGMA auto_vacuum").Scan(&mode); err != nil {
+		return fmt.Errorf("failed to read auto_vacuum mode: %w", err)
+	}
+
+	if mode != autoVacuumIncremental {
+		if _, err := conn.ExecContext(ctx, "VACUUM"); err != nil {
+			return fmt.Errorf("failed to vacuum database: %w", err)
+		}
+		return nil
+	}
+
+	// incremental_vacuum frees one page per step, drain the rows so every free page is released
+	rows, err := conn.QueryContext(ctx, "PRAGMA incremental_vacuum")
+	if err != nil {
+		return fmt.Errorf("failed to run incremental vacuum: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to run incremental vacuum: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/model"
+)
+
+func TestDeleteSubWritesAuditLog(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg), NewSystemHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	subs := createTestSubs(t, "http://a.example/sub", "http://b.example/sub")
+	for _, sub := range subs {
+		if w := doRequest(t, engine, http.MethodDelete, fmt.Sprintf("/api/sub/%d", sub.ID), token, nil); w.Code != http.StatusOK {
+			t.Fatalf("delete status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+	}
+
+	var userID int64
+	var action, target string
+	err := database.DB.QueryRow("SELECT user_id, action, target FROM audit_log WHERE target = ?", fmt.Sprintf("sub:%d", subs[0].ID)).
+		Scan(&userID, &action, &target)
+	if err != nil {
+		t.Fatalf("audit row for the deleted sub not found: %v", err)
+	}
+	if userID != model.AdminUserID || action != model.AuditSubDelete {
+		t.Errorf("audit row = user %d action %q, want user %d action %q", userID, action, model.AdminUserID, model.AuditSubDelete)
+	}
+
+	// Newest entries come first, one per page
+	w := doRequest(t, engine, http.MethodGet, "/api/system/audit?limit=1&offset=0", token, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("audit status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	page := decodeResponse[model.PagedResponse[model.AuditLog]](t, w).Data
+	if page.Total != 2 || page.Limit != 1 || len(page.Items) != 1 {
+		t.Fatalf("page = total %d limit %d items %d, want 2, 1, 1", page.Total, page.Limit, len(page.Items))
+	}
+	if want := fmt.Sprintf("sub:%d", subs[1].ID); page.Items[0].Target != want {
+		t.Errorf("first entry target = %q, want %q", page.Items[0].Target, want)
+	}
+}
+
+func TestListAuditLogsRequiresAdmin(t *testing.T) {
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSystemHandler(database.DB, cfg))
+
+	w := doRequest(t, engine, http.MethodGet, "/api/system/audit", testToken(t, cfg, model.AdminUserID+1), nil)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", w.Code)
+	}
+}
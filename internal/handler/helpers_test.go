@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/router"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const testJWTSecret = "0123456789abcdef0123456789abcdef"
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+
+	dir, err := os.MkdirTemp("", "bestsub-handler-test")
+	if err != nil {
+		panic(err)
+	}
+
+	dbConfig := database.DefaultConfig(filepath.Join(dir, "test.db"))
+	dbConfig.BcryptCost = bcrypt.MinCost
+	if err := database.InitDatabaseWithConfig(dbConfig); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+
+	database.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// newTestConfig Returns a config for handler tests, web assets are disabled
+func newTestConfig() *model.Config {
+	cfg := &model.Config{}
+	cfg.Server.APIOnly = true
+	cfg.JWT.Secret = testJWTSecret
+	cfg.JWT.ExpiresIn = 1
+	cfg.Password.Algorithm = model.PasswordHashBcrypt
+	cfg.Password.BcryptCost = bcrypt.MinCost
+	cfg.Fetcher.MaxConcurrentPerHost = 2
+	cfg.Scheduler.DefaultCron = "0 */1 * * *"
+	return cfg
+}
+
+// newTestEngine Registers the route groups of handlers on a new engine
+func newTestEngine(t *testing.T, handlers ...router.GroupedRouter) *gin.Engine {
+	t.Helper()
+
+	engine := gin.New()
+	for _, h := range handlers {
+		if err := router.RegisterGroup(engine, h); err != nil {
+			t.Fatalf("failed to register routes: %v", err)
+		}
+	}
+	return engine
+}
+
+// testToken Signs a token for userID with the secret of cfg
+func testToken(t *testing.T, cfg *model.Config, userID int64) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString([]byte(cfg.JWT.Secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+// doRequest Sends a request to engine, body is encoded as JSON unless it is a string
+func doRequest(t *testing.T, engine http.Handler, method, path, token string, body any, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var reader *bytes.Reader
+	switch b := body.(type) {
+	case nil:
+		reader = bytes.NewReader(nil)
+	case string:
+		reader = bytes.NewReader([]byte(b))
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("failed to encode request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+// testResponse Standard response envelope with typed data
+type testResponse[T any] struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    T      `json:"data"`
+}
+
+// decodeResponse Decodes the standard envelope of w
+func decodeResponse[T any](t *testing.T, w *httptest.ResponseRecorder) testResponse[T] {
+	t.Helper()
+
+	var resp testResponse[T]
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response %q: %v", w.Body.String(), err)
+	}
+	return resp
+}
+
+// resetTables Empties the tables touched by handler tests
+func resetTables(t *testing.T) {
+	t.Helper()
+
+	for _, table := range []string{"subs", "audit_log"} {
+		if _, err := database.DB.Exec("DELETE FROM " + table); err != nil {
+			t.Fatalf("failed to empty %s: %v", table, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrInvalidID ID is not a positive 64-bit integer
+var ErrInvalidID = errors.New("invalid ID")
+
+// parseID Parses a positive 64-bit integer ID, out of range values are rejected
+func parseID(s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidID
+	}
+	return id, nil
+}
+
+// parseSubIDParam Reads the :id path parameter, responding with 400 and returning false when it is not a valid ID
+func parseSubIDParam(c *gin.Context) (int64, bool) {
+	id, err := parseID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid subscription ID",
+			Data:    nil,
+		})
+		return 0, false
+	}
+	return id, true
+}
+
+// FlexibleID ID in a JSON body, accepted both as a number and as a numeric string
+// Clients that cannot represent int64 exactly as a JSON number can send "9007199254740993"
+type FlexibleID int64
+
+// UnmarshalJSON Decodes 42 and "42" alike, anything that is not a positive 64-bit integer is an error
+func (id *FlexibleID) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(s)
+	}
+
+	parsed, err := parseID(string(data))
+	if err != nil {
+		return err
+	}
+	*id = FlexibleID(parsed)
+	return nil
+}
+
+// int64IDs Converts body IDs for the repository layer
+func int64IDs(ids []FlexibleID) []int64 {
+	out := make([]int64, len(ids))
+	for i, id := range ids {
+		out[i] = int64(id)
+	}
+	return out
+}
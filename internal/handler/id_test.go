@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/model"
+)
+
+func TestFlexibleIDUnmarshal(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    FlexibleID
+		wantErr bool
+	}{
+		{`42`, 42, false},
+		{`"42"`, 42, false},
+		{`"9223372036854775807"`, 9223372036854775807, false},
+		{`9223372036854775807`, 9223372036854775807, false},
+		{`"9223372036854775808"`, 0, true},
+		{`9223372036854775808`, 0, true},
+		{`0`, 0, true},
+		{`"-1"`, 0, true},
+		{`1.5`, 0, true},
+		{`"abc"`, 0, true},
+		{`""`, 0, true},
+		{`null`, 0, true},
+	}
+
+	for _, tt := range tests {
+		var id FlexibleID
+		err := json.Unmarshal([]byte(tt.input), &id)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidID) {
+				t.Errorf("Unmarshal(%s) error = %v, want %v", tt.input, err, ErrInvalidID)
+			}
+			continue
+		}
+		if err != nil || id != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, %v, want %d", tt.input, id, err, tt.want)
+		}
+	}
+}
+
+func TestSubIDPathParam(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	sub := createTestSubs(t, "http://a.example/sub")[0]
+
+	tests := []struct {
+		id   string
+		want int
+	}{
+		{fmt.Sprint(sub.ID), http.StatusOK},
+		{"9223372036854775807", http.StatusNotFound},
+		{"9223372036854775808", http.StatusBadRequest},
+		{"0", http.StatusBadRequest},
+		{"-1", http.StatusBadRequest},
+		{"abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		w := doRequest(t, engine, http.MethodGet, "/api/sub/"+tt.id, token, nil)
+		if w.Code != tt.want {
+			t.Errorf("GET /api/sub/%s status = %d, want %d", tt.id, w.Code, tt.want)
+			continue
+		}
+		if tt.want == http.StatusBadRequest {
+			if msg := decodeResponse[any](t, w).Message; msg != "Invalid subscription ID" {
+				t.Errorf("GET /api/sub/%s message = %q, want %q", tt.id, msg, "Invalid subscription ID")
+			}
+		}
+	}
+}
+
+func TestReorderSubsStringIDs(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	subs := createTestSubs(t, "http://a.example/sub", "http://b.example/sub")
+	a, b := subs[0].ID, subs[1].ID
+
+	body := fmt.Sprintf(`{"ids":["%d",%d]}`, b, a)
+	if w := doRequest(t, engine, http.MethodPost, "/api/sub/reorder", token, body); w.Code != http.StatusOK {
+		t.Fatalf("reorder status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if got, want := listSubIDs(t, engine, token, ""), []int64{b, a}; !slices.Equal(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+
+	body = `{"ids":["9223372036854775808"]}`
+	if w := doRequest(t, engine, http.MethodPost, "/api/sub/reorder", token, body); w.Code != http.StatusBadRequest {
+		t.Errorf("out of range ID status = %d, want 400", w.Code)
+	}
+}
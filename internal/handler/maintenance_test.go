@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/middleware"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/router"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+
+	engine := gin.New()
+	engine.Use(middleware.Maintenance("/api/user/login", "/api/system/maintenance"))
+	for _, h := range []router.GroupedRouter{NewSubHandler(database.DB, cfg), NewSystemHandler(database.DB, cfg)} {
+		if err := router.RegisterGroup(engine, h); err != nil {
+			t.Fatalf("failed to register routes: %v", err)
+		}
+	}
+	token := testToken(t, cfg, model.AdminUserID)
+	sub := createTestSubs(t, "http://a.example/sub")[0]
+
+	setMaintenance := func(enabled bool) {
+		t.Helper()
+		w := doRequest(t, engine, http.MethodPut, "/api/system/maintenance", token, map[string]bool{"enabled": enabled})
+		if w.Code != http.StatusOK {
+			t.Fatalf("maintenance toggle status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+	}
+	setMaintenance(true)
+	t.Cleanup(func() { middleware.SetMaintenanceMode(false) })
+
+	create := doRequest(t, engine, http.MethodPost, "/api/sub/add", token, map[string]any{"url": "http://b.example/sub", "auto_update": true})
+	if create.Code != http.StatusServiceUnavailable {
+		t.Errorf("create status = %d, want 503", create.Code)
+	}
+	if n := countSubs(t); n != 1 {
+		t.Errorf("subs = %d, want 1", n)
+	}
+
+	if got := listSubIDs(t, engine, token, ""); len(got) != 1 || got[0] != sub.ID {
+		t.Errorf("list = %v, want [%d]", got, sub.ID)
+	}
+
+	// Fetching content updates the sub, so it is a write even though it is a GET
+	content := doRequest(t, engine, http.MethodGet, fmt.Sprintf("/api/sub/%d/content", sub.ID), token, nil)
+	if content.Code != http.StatusServiceUnavailable {
+		t.Errorf("content status = %d, want 503", content.Code)
+	}
+
+	setMaintenance(false)
+	create = doRequest(t, engine, http.MethodPost, "/api/sub/add", token, map[string]any{"url": "http://b.example/sub", "auto_update": true})
+	if create.Code != http.StatusCreated {
+		t.Errorf("create status after maintenance = %d, want 201: %s", create.Code, create.Body.String())
+	}
+}
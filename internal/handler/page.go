@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// parseLimit Reads the limit query parameter, responding with 400 and returning false when it is out of range
+func parseLimit(c *gin.Context, defaultLimit, maxLimit int) (int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultLimit)))
+	if err != nil || limit <= 0 || limit > maxLimit {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid limit value",
+			Data:    nil,
+		})
+		return 0, false
+	}
+	return limit, true
+}
+
+// parsePageParams Reads the limit and offset query parameters, responding with 400 and returning false when invalid
+func parsePageParams(c *gin.Context, defaultLimit, maxLimit int) (int, int, bool) {
+	limit, ok := parseLimit(c, defaultLimit, maxLimit)
+	if !ok {
+		return 0, 0, false
+	}
+
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid offset value",
+			Data:    nil,
+		})
+		return 0, 0, false
+	}
+
+	return limit, offset, true
+}
@@ -4,8 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/bestruirui/bestsub/internal/logger"
@@ -20,20 +25,24 @@ import (
 
 // SubHandler Handles subscription related HTTP requests
 type SubHandler struct {
-	subRepo    repository.SubRepository
-	subFetcher *service.SubFetcher
-	config     *model.Config
+	subRepo     repository.SubRepository
+	subFetcher  *service.SubFetcher
+	idempotency *service.IdempotencyStore
+	auditSvc    *service.AuditService
+	config      *model.Config
 }
 
 // NewSubHandler Creates a new subscription handler instance
 func NewSubHandler(db *sql.DB, config *model.Config) *SubHandler {
 	subRepo := repository.NewSubRepository(db)
-	subFetcher := service.NewSubFetcher(subRepo)
+	subFetcher := service.NewSubFetcher(subRepo, config)
 
 	return &SubHandler{
-		subRepo:    subRepo,
-		subFetcher: subFetcher,
-		config:     config,
+		subRepo:     subRepo,
+		subFetcher:  subFetcher,
+		idempotency: service.NewIdempotencyStore(service.IdempotencyKeyTTL),
+		auditSvc:    service.NewAuditService(repository.NewAuditRepository(db)),
+		config:      config,
 	}
 }
 
@@ -48,17 +57,32 @@ func (h *SubHandler) Groups() []*router.GroupRouter {
 func (h *SubHandler) SubGroup() *router.GroupRouter {
 	// Use chain API to create route group
 	return router.NewGroupRouter("/api/sub").
-		Use(middleware.JWTAuth(h.config)).
+		Use(middleware.JWTAuth(h.config), middleware.RouteTimeout(h.config, "/api/sub")).
 		AddRoute(
 			router.NewRoute("/add", router.POST).
 				Handle(h.CreateSub).
 				WithDescription("Create subscription"),
 		).
+		AddRoute(
+			router.NewRoute("/import-url", router.POST).
+				Handle(h.ImportSubsFromURL).
+				WithDescription("Import subscriptions from a remote URL list"),
+		).
 		AddRoute(
 			router.NewRoute("/list", router.GET).
 				Handle(h.GetAllSubs).
 				WithDescription("Get all subscriptions"),
 		).
+		AddRoute(
+			router.NewRoute("/schedule", router.GET).
+				Handle(h.GetSubSchedule).
+				WithDescription("Get auto-update subscription schedule"),
+		).
+		AddRoute(
+			router.NewRoute("/reorder", router.POST).
+				Handle(h.ReorderSubs).
+				WithDescription("Reorder subscriptions"),
+		).
 		AddRoute(
 			router.NewRoute("/:id", router.GET).
 				Handle(h.GetSub).
@@ -66,14 +90,47 @@ func (h *SubHandler) SubGroup() *router.GroupRouter {
 		).
 		AddRoute(
 			router.NewRoute("/:id/content", router.GET).
+				Use(middleware.MaintenanceBlock()).
 				Handle(h.FetchSubContent).
 				WithDescription("Fetch subscription content"),
 		).
+		AddRoute(
+			router.NewRoute("/:id/snapshot", router.POST).
+				Use(middleware.AdminIPAllowList(h.config), middleware.AdminOnly()).
+				Handle(h.SnapshotSubContent).
+				WithDescription("Save subscription content snapshot"),
+		).
+		AddRoute(
+			router.NewRoute("/:id/load-snapshot", router.POST).
+				Use(middleware.AdminIPAllowList(h.config), middleware.AdminOnly()).
+				Handle(h.LoadSubContentSnapshot).
+				WithDescription("Load subscription content snapshot"),
+		).
+		AddRoute(
+			router.NewRoute("/:id/stats", router.PUT).
+				Handle(h.UpdateSubStats).
+				WithDescription("Set subscription node statistics"),
+		).
+		AddRoute(
+			router.NewRoute("/:id/pin", router.POST).
+				Handle(h.PinSub).
+				WithDescription("Pin subscription content"),
+		).
+		AddRoute(
+			router.NewRoute("/:id/unpin", router.POST).
+				Handle(h.UnpinSub).
+				WithDescription("Unpin subscription content"),
+		).
 		AddRoute(
 			router.NewRoute("/:id", router.PUT).
 				Handle(h.UpdateSub).
 				WithDescription("Update subscription"),
 		).
+		AddRoute(
+			router.NewRoute("/:id", router.PATCH).
+				Handle(h.PatchSub).
+				WithDescription("Partially update subscription"),
+		).
 		AddRoute(
 			router.NewRoute("/:id", router.DELETE).
 				Handle(h.DeleteSub).
@@ -96,17 +153,11 @@ func (h *SubHandler) SubGroup() *router.GroupRouter {
 // @Router /api/sub/{id} [get]
 // @Security BearerAuth
 func (h *SubHandler) GetSub(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	ctx, cancel := requestContext(c, h.config, RequestTimeout)
 	defer cancel()
 
-	idStr := c.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, model.StandardResponse{
-			Code:    http.StatusBadRequest,
-			Message: "Invalid subscription ID",
-			Data:    nil,
-		})
+	id, ok := parseSubIDParam(c)
+	if !ok {
 		return
 	}
 
@@ -138,29 +189,86 @@ func (h *SubHandler) GetSub(c *gin.Context) {
 
 // CreateSubRequest Request to create a new subscription
 type CreateSubRequest struct {
-	URL        string `json:"url" binding:"required"`
-	Cron       string `json:"cron" binding:"required"`
-	AutoUpdate bool   `json:"auto_update" binding:"required"`
+	URL        string            `json:"url" binding:"required"`
+	URLVars    map[string]string `json:"url_vars"`
+	Cron       string            `json:"cron"`
+	AutoUpdate bool              `json:"auto_update" binding:"required"`
+	// FetchTimeoutSeconds 0 uses the global fetcher timeout
+	FetchTimeoutSeconds int    `json:"fetch_timeout_seconds" binding:"min=0"`
+	Remark              string `json:"remark" binding:"max=256"`
+	// SuccessPattern Regex the fetched content must match, empty accepts any 200 response
+	SuccessPattern string `json:"success_pattern"`
+	// FetchMethod GET (default) or POST
+	FetchMethod string `json:"fetch_method" binding:"omitempty,oneof=GET POST"`
+	// FetchBody Sent with POST fetches, JSON bodies get a JSON content type, anything else is sent as a form
+	FetchBody string `json:"fetch_body" binding:"max=4096"`
 }
 
 // CreateSub godoc
 // @Summary 创建新订阅
-// @Description 使用提供的URL创建新订阅
+// @Description 使用提供的URL创建新订阅，未提供cron时使用配置中的默认cron，携带相同Idempotency-Key的重试请求将返回首次创建的订阅，并发的重复请求会等待首次请求完成
 // @Tags 订阅
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "幂等键"
 // @Param sub body CreateSubRequest true "订阅数据"
 // @Success 201 {object} model.SuccessResponse{data=model.Sub} "订阅创建成功"
 // @Failure 400 {object} model.BadRequestResponse{} "无效请求"
 // @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
-// @Failure 409 {object} model.ConflictResponse{} "订阅已存在"
+// @Failure 409 {object} model.ConflictResponse{} "订阅已存在或相同幂等键的请求仍在处理中"
 // @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
 // @Router /api/sub/add [post]
 // @Security BearerAuth
 func (h *SubHandler) CreateSub(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	ctx, cancel := requestContext(c, h.config, RequestTimeout)
 	defer cancel()
 
+	// Keys are scoped per user so different clients cannot collide
+	var reservation *service.IdempotencyReservation
+	if key := c.GetHeader("Idempotency-Key"); key != "" {
+		key = fmt.Sprintf("%d:%s", c.GetInt64("user_id"), key)
+
+		for reservation == nil {
+			res, subID, err := h.idempotency.Reserve(ctx, key)
+			if err != nil {
+				c.JSON(http.StatusConflict, model.ConflictResponse{
+					Code:    http.StatusConflict,
+					Message: "A request with this Idempotency-Key is still in progress",
+					Data:    nil,
+				})
+				return
+			}
+			if res != nil {
+				reservation = res
+				break
+			}
+
+			sub, err := h.subRepo.GetByID(ctx, subID)
+			if err == nil {
+				c.JSON(http.StatusCreated, model.SuccessResponse{
+					Code:    http.StatusCreated,
+					Message: "Subscription created successfully",
+					Data:    sub,
+				})
+				return
+			}
+			if !errors.Is(err, model.ErrSubNotFound) {
+				c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
+					Code:    http.StatusInternalServerError,
+					Message: "Failed to retrieve subscription",
+					Data:    nil,
+				})
+				logger.Error("Failed to get subscription for idempotency key: %v, SubID: %d", err, subID)
+				return
+			}
+
+			// The sub created for this key was deleted since, create it again
+			h.idempotency.Forget(key, subID)
+		}
+		// Failed requests free the key so a retry can create the sub
+		defer reservation.Release()
+	}
+
 	var req CreateSubRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
@@ -171,6 +279,21 @@ func (h *SubHandler) CreateSub(c *gin.Context) {
 		return
 	}
 
+	// 校验URL模板
+	if _, err := service.RenderSubURL(req.URL, req.URLVars); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid subscription URL template: " + err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	// 未提供cron时使用默认值
+	if req.Cron == "" {
+		req.Cron = h.config.Scheduler.DefaultCron
+	}
+
 	// 验证cron表达式
 	if err := validator.ValidateCron(req.Cron); err != nil {
 		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
@@ -181,12 +304,28 @@ func (h *SubHandler) CreateSub(c *gin.Context) {
 		return
 	}
 
+	if _, err := regexp.Compile(req.SuccessPattern); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid success pattern: " + err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
 	sub := &model.Sub{
 		URL:        req.URL,
+		URLVars:    req.URLVars,
 		TotalNodes: 0,
 		AliveNodes: 0,
 		Cron:       req.Cron,
 		AutoUpdate: req.AutoUpdate,
+
+		FetchTimeoutSeconds: req.FetchTimeoutSeconds,
+		Remark:              req.Remark,
+		SuccessPattern:      req.SuccessPattern,
+		FetchMethod:         req.FetchMethod,
+		FetchBody:           req.FetchBody,
 	}
 
 	if err := h.subRepo.Create(ctx, sub); err != nil {
@@ -207,6 +346,12 @@ func (h *SubHandler) CreateSub(c *gin.Context) {
 		return
 	}
 
+	if reservation != nil {
+		reservation.Complete(sub.ID)
+	}
+
+	h.auditSvc.Record(c.GetInt64("user_id"), model.AuditSubCreate, fmt.Sprintf("sub:%d", sub.ID))
+
 	c.JSON(http.StatusCreated, model.SuccessResponse{
 		Code:    http.StatusCreated,
 		Message: "Subscription created successfully",
@@ -215,15 +360,73 @@ func (h *SubHandler) CreateSub(c *gin.Context) {
 }
 
 // UpdateSubRequest Request to update a subscription
+// Empty strings and omitted fields leave the current value unchanged
 type UpdateSubRequest struct {
-	URL        string `json:"url"`
-	Cron       string `json:"cron"`
-	AutoUpdate *bool  `json:"auto_update"`
+	URL string `json:"url"`
+	// URLVars Replaces all variables, a value of "***" keeps the stored value
+	URLVars    map[string]string `json:"url_vars"`
+	Cron       string            `json:"cron"`
+	AutoUpdate *bool             `json:"auto_update"`
+	// FetchTimeoutSeconds 0 resets to the global fetcher timeout
+	FetchTimeoutSeconds *int   `json:"fetch_timeout_seconds" binding:"omitempty,min=0"`
+	Remark              string `json:"remark" binding:"max=256"`
+	SuccessPattern      string `json:"success_pattern"`
+	FetchMethod         string `json:"fetch_method" binding:"omitempty,oneof=GET POST"`
+	FetchBody           string `json:"fetch_body" binding:"max=4096"`
+}
+
+// toPatch Converts the PUT request into the equivalent partial update
+func (r *UpdateSubRequest) toPatch() *PatchSubRequest {
+	patch := &PatchSubRequest{
+		AutoUpdate:          r.AutoUpdate,
+		FetchTimeoutSeconds: r.FetchTimeoutSeconds,
+	}
+	if r.URL != "" {
+		patch.URL = &r.URL
+	}
+	if r.URLVars != nil {
+		patch.URLVars = &r.URLVars
+	}
+	if r.Cron != "" {
+		patch.Cron = &r.Cron
+	}
+	if r.Remark != "" {
+		patch.Remark = &r.Remark
+	}
+	if r.SuccessPattern != "" {
+		patch.SuccessPattern = &r.SuccessPattern
+	}
+	if r.FetchMethod != "" {
+		patch.FetchMethod = &r.FetchMethod
+	}
+	if r.FetchBody != "" {
+		patch.FetchBody = &r.FetchBody
+	}
+	return patch
+}
+
+// PatchSubRequest Partial update of a subscription
+// Only fields present in the body are applied; an explicit null is treated as absent
+type PatchSubRequest struct {
+	URL *string `json:"url" binding:"omitempty,min=1"`
+	// URLVars Replaces all variables, a value of "***" keeps the stored value
+	URLVars    *map[string]string `json:"url_vars"`
+	Cron       *string            `json:"cron"`
+	AutoUpdate *bool              `json:"auto_update"`
+	// FetchTimeoutSeconds 0 resets to the global fetcher timeout
+	FetchTimeoutSeconds *int `json:"fetch_timeout_seconds" binding:"omitempty,min=0"`
+	// Remark An empty string clears the remark
+	Remark *string `json:"remark" binding:"omitempty,max=256"`
+	// SuccessPattern An empty string removes the pattern
+	SuccessPattern *string `json:"success_pattern"`
+	FetchMethod    *string `json:"fetch_method" binding:"omitempty,oneof=GET POST"`
+	// FetchBody An empty string removes the body
+	FetchBody *string `json:"fetch_body" binding:"omitempty,max=4096"`
 }
 
 // UpdateSub godoc
 // @Summary 更新订阅
-// @Description 更新订阅URL
+// @Description 更新订阅，空字符串或未提供的字段保持不变
 // @Tags 订阅
 // @Accept json
 // @Produce json
@@ -237,20 +440,68 @@ type UpdateSubRequest struct {
 // @Router /api/sub/{id} [put]
 // @Security BearerAuth
 func (h *SubHandler) UpdateSub(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
-	defer cancel()
+	var req UpdateSubRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request data",
+			Data:    nil,
+		})
+		return
+	}
 
-	idStr := c.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, model.StandardResponse{
+	h.applySubPatch(c, req.toPatch())
+}
+
+// PatchSub godoc
+// @Summary 部分更新订阅
+// @Description 仅更新请求体中出现的字段，未出现的字段保持不变
+// @Tags 订阅
+// @Accept json
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Param sub body PatchSubRequest true "需要更新的字段"
+// @Success 200 {object} model.SuccessResponse{data=model.Sub} "订阅已更新"
+// @Failure 400 {object} model.BadRequestResponse{} "无效请求"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 404 {object} model.NotFoundResponse{} "订阅不存在"
+// @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
+// @Router /api/sub/{id} [patch]
+// @Security BearerAuth
+func (h *SubHandler) PatchSub(c *gin.Context) {
+	var req PatchSubRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
 			Code:    http.StatusBadRequest,
-			Message: "Invalid subscription ID",
+			Message: "Invalid request data",
 			Data:    nil,
 		})
 		return
 	}
 
+	h.applySubPatch(c, &req)
+}
+
+// keepRedactedURLVars Replaces masked values sent back by clients with the stored values
+func keepRedactedURLVars(vars, stored model.URLVars) model.URLVars {
+	for name, value := range vars {
+		if old, ok := stored[name]; ok && value == model.RedactedURLVar {
+			vars[name] = old
+		}
+	}
+	return vars
+}
+
+// applySubPatch Loads the subscription from the path ID, applies the present fields and saves it
+func (h *SubHandler) applySubPatch(c *gin.Context, patch *PatchSubRequest) {
+	ctx, cancel := requestContext(c, h.config, RequestTimeout)
+	defer cancel()
+
+	id, ok := parseSubIDParam(c)
+	if !ok {
+		return
+	}
+
 	sub, err := h.subRepo.GetByID(ctx, id)
 	if err != nil {
 		status := http.StatusInternalServerError
@@ -270,22 +521,23 @@ func (h *SubHandler) UpdateSub(c *gin.Context) {
 		return
 	}
 
-	var req UpdateSubRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if patch.URL != nil {
+		sub.URL = *patch.URL
+	}
+	if patch.URLVars != nil {
+		sub.URLVars = keepRedactedURLVars(*patch.URLVars, sub.URLVars)
+	}
+	if _, err := service.RenderSubURL(sub.URL, sub.URLVars); err != nil {
 		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
 			Code:    http.StatusBadRequest,
-			Message: "Invalid request data",
+			Message: "Invalid subscription URL template: " + err.Error(),
 			Data:    nil,
 		})
 		return
 	}
-
-	if req.URL != "" {
-		sub.URL = req.URL
-	}
-	if req.Cron != "" {
+	if patch.Cron != nil {
 		// 验证cron表达式
-		if err := validator.ValidateCron(req.Cron); err != nil {
+		if err := validator.ValidateCron(*patch.Cron); err != nil {
 			c.JSON(http.StatusBadRequest, model.BadRequestResponse{
 				Code:    http.StatusBadRequest,
 				Message: "Invalid cron expression: " + err.Error(),
@@ -293,10 +545,33 @@ func (h *SubHandler) UpdateSub(c *gin.Context) {
 			})
 			return
 		}
-		sub.Cron = req.Cron
+		sub.Cron = *patch.Cron
+	}
+	if patch.AutoUpdate != nil {
+		sub.AutoUpdate = *patch.AutoUpdate
+	}
+	if patch.FetchTimeoutSeconds != nil {
+		sub.FetchTimeoutSeconds = *patch.FetchTimeoutSeconds
+	}
+	if patch.Remark != nil {
+		sub.Remark = *patch.Remark
+	}
+	if patch.SuccessPattern != nil {
+		if _, err := regexp.Compile(*patch.SuccessPattern); err != nil {
+			c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid success pattern: " + err.Error(),
+				Data:    nil,
+			})
+			return
+		}
+		sub.SuccessPattern = *patch.SuccessPattern
 	}
-	if req.AutoUpdate != nil {
-		sub.AutoUpdate = *req.AutoUpdate
+	if patch.FetchMethod != nil {
+		sub.FetchMethod = *patch.FetchMethod
+	}
+	if patch.FetchBody != nil {
+		sub.FetchBody = *patch.FetchBody
 	}
 
 	if err := h.subRepo.Update(ctx, sub); err != nil {
@@ -309,6 +584,8 @@ func (h *SubHandler) UpdateSub(c *gin.Context) {
 		return
 	}
 
+	h.auditSvc.Record(c.GetInt64("user_id"), model.AuditSubUpdate, fmt.Sprintf("sub:%d", id))
+
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Code:    http.StatusOK,
 		Message: "Subscription updated successfully",
@@ -331,17 +608,11 @@ func (h *SubHandler) UpdateSub(c *gin.Context) {
 // @Router /api/sub/{id} [delete]
 // @Security BearerAuth
 func (h *SubHandler) DeleteSub(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	ctx, cancel := requestContext(c, h.config, RequestTimeout)
 	defer cancel()
 
-	idStr := c.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
-			Code:    http.StatusBadRequest,
-			Message: "Invalid subscription ID",
-			Data:    nil,
-		})
+	id, ok := parseSubIDParam(c)
+	if !ok {
 		return
 	}
 
@@ -363,6 +634,8 @@ func (h *SubHandler) DeleteSub(c *gin.Context) {
 		return
 	}
 
+	h.auditSvc.Record(c.GetInt64("user_id"), model.AuditSubDelete, fmt.Sprintf("sub:%d", id))
+
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Code:    http.StatusOK,
 		Message: "Subscription deleted successfully",
@@ -371,27 +644,158 @@ func (h *SubHandler) DeleteSub(c *gin.Context) {
 }
 
 // UpdateStatsRequest Request to update subscription stats
+// Pointers so that an explicit 0 passes the required check
 type UpdateStatsRequest struct {
-	TotalNodes int `json:"total_nodes" binding:"required,min=0"`
-	AliveNodes int `json:"alive_nodes" binding:"required,min=0"`
+	TotalNodes *int `json:"total_nodes" binding:"required,min=0"`
+	AliveNodes *int `json:"alive_nodes" binding:"required,min=0"`
+}
+
+// UpdateSubStats godoc
+// @Summary 设置订阅节点统计
+// @Description 手动设置订阅的节点总数和存活数，用于测试和修正，存活数不能大于总数
+// @Tags 订阅
+// @Accept json
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Param request body UpdateStatsRequest true "节点统计"
+// @Success 200 {object} model.SuccessResponse{data=model.Sub} "统计已更新"
+// @Failure 400 {object} model.BadRequestResponse{} "无效请求"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 404 {object} model.NotFoundResponse{} "订阅不存在"
+// @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
+// @Router /api/sub/{id}/stats [put]
+// @Security BearerAuth
+func (h *SubHandler) UpdateSubStats(c *gin.Context) {
+	ctx, cancel := requestContext(c, h.config, RequestTimeout)
+	defer cancel()
+
+	id, ok := parseSubIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateStatsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request data",
+			Data:    nil,
+		})
+		return
+	}
+
+	if *req.AliveNodes > *req.TotalNodes {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Alive nodes cannot exceed total nodes",
+			Data:    nil,
+		})
+		return
+	}
+
+	if err := h.subRepo.UpdateStats(ctx, id, *req.TotalNodes, *req.AliveNodes); err != nil {
+		if errors.Is(err, model.ErrSubNotFound) {
+			c.JSON(http.StatusNotFound, model.NotFoundResponse{
+				Code:    http.StatusNotFound,
+				Message: "Subscription not found",
+				Data:    nil,
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update subscription statistics",
+			Data:    nil,
+		})
+		logger.Error("Failed to update subscription statistics: %v, SubID: %d", err, id)
+		return
+	}
+
+	sub, err := h.subRepo.GetByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve subscription",
+			Data:    nil,
+		})
+		logger.Error("Failed to get subscription after stats update: %v, SubID: %d", err, id)
+		return
+	}
+
+	h.auditSvc.Record(c.GetInt64("user_id"), model.AuditSubUpdate, fmt.Sprintf("sub:%d", id))
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Subscription statistics updated",
+		Data:    sub,
+	})
+}
+
+const (
+	// defaultSubPageSize Page size used for cursor pagination when limit is not given
+	defaultSubPageSize = 20
+	// maxSubPageSize Largest accepted page size for cursor pagination
+	maxSubPageSize = 100
+)
+
+// SubPage One page of subscriptions in cursor pagination mode
+type SubPage struct {
+	Items []*model.Sub `json:"items"`
+	// NextCursor Value to pass as after_id for the next page, null on the last page
+	NextCursor *int64 `json:"next_cursor"`
 }
 
 // GetAllSubs godoc
 // @Summary 获取所有订阅
-// @Description 获取所有订阅的列表
+// @Description 获取所有订阅的列表，可按存活节点数过滤。传入after_id或limit时按ID进行游标分页，返回SubPage结构
 // @Tags 订阅
 // @Accept json
 // @Produce json
+// @Param min_alive query int false "最少存活节点数"
+// @Param after_id query int false "游标，返回ID大于该值的订阅"
+// @Param limit query int false "每页数量，默认20，最大100"
 // @Success 200 {object} model.SuccessResponse{data=[]model.Sub} "成功"
 // @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
 // @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 400 {object} model.BadRequestResponse{} "无效请求"
 // @Router /api/sub/list [get]
 // @Security BearerAuth
 func (h *SubHandler) GetAllSubs(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
+	ctx, cancel := requestContext(c, h.config, RequestTimeout)
 	defer cancel()
 
-	subs, err := h.subRepo.GetAll(ctx)
+	var subs []*model.Sub
+	var err error
+
+	minAlive := 0
+	minAliveStr := c.Query("min_alive")
+	if minAliveStr != "" {
+		var convErr error
+		minAlive, convErr = strconv.Atoi(minAliveStr)
+		if convErr != nil || minAlive < 0 {
+			c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid min_alive value",
+				Data:    nil,
+			})
+			return
+		}
+	}
+
+	_, hasCursor := c.GetQuery("after_id")
+	_, hasLimit := c.GetQuery("limit")
+	if hasCursor || hasLimit {
+		h.getSubPage(ctx, c, minAlive)
+		return
+	}
+
+	if minAliveStr != "" {
+		subs, err = h.subRepo.GetByMinAlive(ctx, minAlive)
+	} else {
+		subs, err = h.subRepo.GetAll(ctx)
+	}
+
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -409,39 +813,174 @@ func (h *SubHandler) GetAllSubs(c *gin.Context) {
 	})
 }
 
-// FetchSubContent godoc
-// @Summary 获取订阅内容
-// @Description 从订阅URL中获取内容并存储到内存中
+// getSubPage Responds with one page of subscriptions using after_id as the keyset cursor
+func (h *SubHandler) getSubPage(ctx context.Context, c *gin.Context, minAlive int) {
+	afterID, err := strconv.ParseInt(c.DefaultQuery("after_id", "0"), 10, 64)
+	if err != nil || afterID < 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid after_id value",
+			Data:    nil,
+		})
+		return
+	}
+
+	limit, ok := parseLimit(c, defaultSubPageSize, maxSubPageSize)
+	if !ok {
+		return
+	}
+
+	// Fetch one extra row to know whether another page follows
+	subs, err := h.subRepo.GetPageAfter(ctx, afterID, limit+1, minAlive)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve subscriptions",
+			Data:    nil,
+		})
+		logger.Error("Failed to get subscription page: %v", err)
+		return
+	}
+
+	page := SubPage{Items: subs}
+	if len(subs) > limit {
+		page.Items = subs[:limit]
+		next := page.Items[limit-1].ID
+		page.NextCursor = &next
+	}
+	if page.Items == nil {
+		page.Items = []*model.Sub{}
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    page,
+	})
+}
+
+// ReorderSubsRequest Request to reorder subscriptions
+type ReorderSubsRequest struct {
+	// IDs Numbers or numeric strings
+	IDs []FlexibleID `json:"ids" binding:"required,min=1" swaggertype:"array,integer"`
+}
+
+// ReorderSubs godoc
+// @Summary 调整订阅顺序
+// @Description 按给定的ID顺序更新订阅的排序，未列出的订阅保持原有相对顺序排在其后，列表和合并输出均遵循该顺序
 // @Tags 订阅
 // @Accept json
 // @Produce json
-// @Param id path int true "订阅ID"
-// @Success 200 {object} model.SuccessResponse{data=model.Sub} "成功"
+// @Param request body ReorderSubsRequest true "按新顺序排列的订阅ID"
+// @Success 200 {object} model.SuccessResponse{data=[]model.Sub} "成功"
 // @Failure 400 {object} model.BadRequestResponse{} "无效请求"
-// @Failure 404 {object} model.ServerErrorResponse{} "订阅不存在"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 404 {object} model.NotFoundResponse{} "订阅不存在"
 // @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
-// @Router /api/sub/{id}/content [get]
+// @Router /api/sub/reorder [post]
 // @Security BearerAuth
-func (h *SubHandler) FetchSubContent(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+func (h *SubHandler) ReorderSubs(c *gin.Context) {
+	ctx, cancel := requestContext(c, h.config, RequestTimeout)
 	defer cancel()
 
-	idStr := c.Param("id")
-	id, err := strconv.ParseInt(idStr, 10, 64)
-	if err != nil {
+	var req ReorderSubsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
 			Code:    http.StatusBadRequest,
-			Message: "Invalid subscription ID",
+			Message: "Invalid request data",
 			Data:    nil,
 		})
 		return
 	}
 
-	// 获取订阅内容
-	sub, err := h.subFetcher.FetchSub(ctx, id)
-	if err != nil {
+	ids := int64IDs(req.IDs)
+	seen := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Duplicate subscription ID in order",
+				Data:    nil,
+			})
+			return
+		}
+		seen[id] = struct{}{}
+	}
+
+	if err := h.subRepo.Reorder(ctx, ids); err != nil {
 		status := http.StatusInternalServerError
-		message := "Failed to fetch subscription content"
+		message := "Failed to reorder subscriptions"
+
+		if errors.Is(err, model.ErrSubNotFound) {
+			status = http.StatusNotFound
+			message = "Subscription not found"
+		}
+
+		c.JSON(status, model.ServerErrorResponse{
+			Code:    status,
+			Message: message,
+			Data:    nil,
+		})
+		logger.Error("Failed to reorder subscriptions: %v", err)
+		return
+	}
+
+	h.auditSvc.Record(c.GetInt64("user_id"), model.AuditSubReorder, fmt.Sprintf("subs:%v", req.IDs))
+
+	subs, err := h.subRepo.GetAll(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve subscriptions",
+			Data:    nil,
+		})
+		logger.Error("Failed to get all subscriptions: %v", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Subscriptions reordered successfully",
+		Data:    subs,
+	})
+}
+
+// FetchSubContent godoc
+// @Summary 获取订阅内容
+// @Description 从订阅URL中获取内容并存储到内存中，会更新订阅的获取状态，维护模式下不可用
+// @Tags 订阅
+// @Accept json
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Param X-Timeout-Seconds header int false "获取超时秒数，不超过配置的上限"
+// @Success 200 {object} model.SuccessResponse{data=model.Sub} "成功"
+// @Failure 400 {object} model.BadRequestResponse{} "无效请求"
+// @Failure 404 {object} model.ServerErrorResponse{} "订阅不存在"
+// @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
+// @Failure 502 {object} model.ServerErrorResponse{} "获取失败，或订阅返回空内容、内容不符合成功匹配规则，已保留之前的内容"
+// @Failure 503 {object} model.StandardResponse{} "维护模式已开启"
+// @Failure 504 {object} model.ServerErrorResponse{} "获取订阅超时"
+// @Router /api/sub/{id}/content [get]
+// @Security BearerAuth
+func (h *SubHandler) FetchSubContent(c *gin.Context) {
+	// The fetcher bounds the fetch with the global or per-sub fetch timeout and its database calls separately,
+	// a shorter request timeout would cut off slow subscriptions
+	ctx := c.Request.Context()
+
+	id, ok := parseSubIDParam(c)
+	if !ok {
+		return
+	}
+
+	// 获取订阅内容，X-Timeout-Seconds 优先于订阅和全局的获取超时
+	var override time.Duration
+	if timeout, ok := requestTimeout(c, h.config, 0); ok {
+		override = timeout
+	}
+	sub, err := h.subFetcher.FetchSubWithTimeout(ctx, id, override)
+	if err != nil {
+		status := http.StatusInternalServerError
+		message := "Failed to fetch subscription content"
 
 		if errors.Is(err, model.ErrSubNotFound) {
 			status = http.StatusNotFound
@@ -450,8 +989,13 @@ func (h *SubHandler) FetchSubContent(c *gin.Context) {
 			status = http.StatusBadRequest
 			message = "Invalid subscription URL"
 		} else if errors.Is(err, model.ErrFetchFailed) {
-			status = http.StatusServiceUnavailable
-			message = "Failed to fetch subscription data"
+			status, message = fetchErrorStatus(err)
+		} else if errors.Is(err, model.ErrEmptyContent) {
+			status = http.StatusBadGateway
+			message = "Subscription returned empty content, previous content kept"
+		} else if errors.Is(err, model.ErrContentReject) {
+			status = http.StatusBadGateway
+			message = "Subscription content does not match success pattern, previous content kept"
 		}
 
 		c.JSON(status, model.ServerErrorResponse{
@@ -469,3 +1013,478 @@ func (h *SubHandler) FetchSubContent(c *gin.Context) {
 		Data:    sub,
 	})
 }
+
+// fetchErrorStatus Maps a failed fetch to the response status and message
+// Upstream failures are 502, an upstream that is too slow is 504
+func fetchErrorStatus(err error) (int, string) {
+	var fetchErr *model.FetchError
+	if !errors.As(err, &fetchErr) {
+		return http.StatusServiceUnavailable, "Failed to fetch subscription data"
+	}
+
+	switch fetchErr.Kind {
+	case model.FetchErrorTimeout:
+		return http.StatusGatewayTimeout, "Subscription fetch timed out"
+	case model.FetchErrorDNS:
+		return http.StatusBadGateway, "Subscription host could not be resolved"
+	case model.FetchErrorStatus:
+		return http.StatusBadGateway, "Subscription server returned an error status"
+	case model.FetchErrorBody:
+		return http.StatusBadGateway, "Failed to read subscription response"
+	default:
+		return http.StatusBadGateway, "Failed to connect to subscription server"
+	}
+}
+
+// snapshotDir Directory content snapshots are written to, next to the database
+func (h *SubHandler) snapshotDir() string {
+	return filepath.Join(filepath.Dir(h.config.Database.Path), "snapshots")
+}
+
+// ContentSnapshotResponse Content snapshot operation result
+type ContentSnapshotResponse struct {
+	Name string `json:"name" example:"sub-1-20240101-000000.000000.txt"`
+}
+
+// SnapshotSubContent godoc
+// @Summary 保存订阅内容快照
+// @Description 将内存中的订阅内容保存为数据目录下带时间戳的文件，便于离线复现解析问题
+// @Tags 订阅
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} model.SuccessResponse{data=ContentSnapshotResponse} "快照已保存"
+// @Failure 400 {object} model.BadRequestResponse{} "无效请求"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 403 {object} model.StandardResponse{} "需要管理员权限"
+// @Failure 404 {object} model.NotFoundResponse{} "订阅内容不存在"
+// @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
+// @Router /api/sub/{id}/snapshot [post]
+// @Security BearerAuth
+func (h *SubHandler) SnapshotSubContent(c *gin.Context) {
+	id, ok := parseSubIDParam(c)
+	if !ok {
+		return
+	}
+
+	name, err := service.SaveContentSnapshot(h.snapshotDir(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrContentNotFound) {
+			c.JSON(http.StatusNotFound, model.NotFoundResponse{
+				Code:    http.StatusNotFound,
+				Message: "Subscription content not found",
+				Data:    nil,
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to save content snapshot",
+			Data:    nil,
+		})
+		logger.Error("Failed to save content snapshot: %v, SubID: %d", err, id)
+		return
+	}
+
+	h.auditSvc.Record(c.GetInt64("user_id"), model.AuditSubSnapshot, fmt.Sprintf("sub:%d", id))
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Snapshot saved",
+		Data:    ContentSnapshotResponse{Name: name},
+	})
+}
+
+// LoadSnapshotRequest Snapshot to restore, empty name restores the latest one
+type LoadSnapshotRequest struct {
+	Name string `json:"name"`
+}
+
+// LoadSubContentSnapshot godoc
+// @Summary 加载订阅内容快照
+// @Description 将快照文件内容恢复到内存中的订阅内容，未指定名称时加载最新快照，并像获取一样更新订阅的获取时间和内容大小
+// @Tags 订阅
+// @Accept json
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Param request body LoadSnapshotRequest false "快照名称"
+// @Success 200 {object} model.SuccessResponse{data=ContentSnapshotResponse} "快照已加载"
+// @Failure 400 {object} model.BadRequestResponse{} "无效请求"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 403 {object} model.StandardResponse{} "需要管理员权限"
+// @Failure 404 {object} model.NotFoundResponse{} "订阅或快照不存在"
+// @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
+// @Router /api/sub/{id}/load-snapshot [post]
+// @Security BearerAuth
+func (h *SubHandler) LoadSubContentSnapshot(c *gin.Context) {
+	ctx, cancel := requestContext(c, h.config, RequestTimeout)
+	defer cancel()
+
+	id, ok := parseSubIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req LoadSnapshotRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid request data",
+				Data:    nil,
+			})
+			return
+		}
+	}
+
+	name, err := service.LoadContentSnapshot(ctx, h.subRepo, h.snapshotDir(), id, req.Name)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrSubNotFound):
+			c.JSON(http.StatusNotFound, model.NotFoundResponse{
+				Code:    http.StatusNotFound,
+				Message: "Subscription not found",
+				Data:    nil,
+			})
+		case errors.Is(err, service.ErrInvalidSnapshotName):
+			c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+				Code:    http.StatusBadRequest,
+				Message: "Invalid snapshot name",
+				Data:    nil,
+			})
+		case errors.Is(err, service.ErrSnapshotNotFound):
+			c.JSON(http.StatusNotFound, model.NotFoundResponse{
+				Code:    http.StatusNotFound,
+				Message: "Snapshot not found",
+				Data:    nil,
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
+				Code:    http.StatusInternalServerError,
+				Message: "Failed to load content snapshot",
+				Data:    nil,
+			})
+			logger.Error("Failed to load content snapshot: %v, SubID: %d", err, id)
+		}
+		return
+	}
+
+	h.auditSvc.Record(c.GetInt64("user_id"), model.AuditSubLoadSnapshot, fmt.Sprintf("sub:%d", id))
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Snapshot loaded",
+		Data:    ContentSnapshotResponse{Name: name},
+	})
+}
+
+// PinSub godoc
+// @Summary 固定订阅内容
+// @Description 固定当前订阅内容并持久化保存，之后的获取仅记录获取时间而不覆盖已存储的内容，固定的内容不会因过期而被清理，重启后首次获取时从持久化副本恢复
+// @Tags 订阅
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} model.SuccessResponse{} "订阅内容已固定"
+// @Failure 400 {object} model.BadRequestResponse{} "无效请求"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 404 {object} model.NotFoundResponse{} "订阅或订阅内容不存在"
+// @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
+// @Router /api/sub/{id}/pin [post]
+// @Security BearerAuth
+func (h *SubHandler) PinSub(c *gin.Context) {
+	h.setSubPinned(c, true)
+}
+
+// UnpinSub godoc
+// @Summary 取消固定订阅内容
+// @Description 取消固定并删除持久化的固定内容，下一次获取将重新覆盖存储的订阅内容
+// @Tags 订阅
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Success 200 {object} model.SuccessResponse{} "订阅内容已取消固定"
+// @Failure 400 {object} model.BadRequestResponse{} "无效请求"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 404 {object} model.NotFoundResponse{} "订阅不存在"
+// @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
+// @Router /api/sub/{id}/unpin [post]
+// @Security BearerAuth
+func (h *SubHandler) UnpinSub(c *gin.Context) {
+	h.setSubPinned(c, false)
+}
+
+// setSubPinned Shared implementation of PinSub and UnpinSub
+func (h *SubHandler) setSubPinned(c *gin.Context, pinned bool) {
+	ctx, cancel := requestContext(c, h.config, RequestTimeout)
+	defer cancel()
+
+	id, ok := parseSubIDParam(c)
+	if !ok {
+		return
+	}
+
+	// The pinned content is persisted so it can be restored after a restart
+	var content string
+	if pinned {
+		var err error
+		content, err = service.GetSubContent(id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, model.NotFoundResponse{
+				Code:    http.StatusNotFound,
+				Message: "Subscription content not found",
+				Data:    nil,
+			})
+			return
+		}
+	}
+
+	if err := h.subRepo.SetPinned(ctx, id, pinned, content); err != nil {
+		if errors.Is(err, model.ErrSubNotFound) {
+			c.JSON(http.StatusNotFound, model.NotFoundResponse{
+				Code:    http.StatusNotFound,
+				Message: "Subscription not found",
+				Data:    nil,
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to update pinned state",
+			Data:    nil,
+		})
+		logger.Error("Failed to update pinned state: %v, SubID: %d", err, id)
+		return
+	}
+
+	action, message := model.AuditSubPin, "Subscription pinned"
+	if !pinned {
+		action, message = model.AuditSubUnpin, "Subscription unpinned"
+	}
+	h.auditSvc.Record(c.GetInt64("user_id"), action, fmt.Sprintf("sub:%d", id))
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: message,
+		Data:    nil,
+	})
+}
+
+// maxImportLines Largest number of URLs accepted from one import list
+const maxImportLines = 500
+
+// ImportURLRequest Request to import the subscriptions listed at a remote URL
+type ImportURLRequest struct {
+	// URL List of subscription URLs, one per line. Blank lines and lines starting with # are skipped
+	URL        string `json:"url" binding:"required"`
+	Cron       string `json:"cron"`
+	AutoUpdate bool   `json:"auto_update"`
+}
+
+// ImportLineResult Result of importing a single line of the list
+type ImportLineResult struct {
+	Line  int    `json:"line"`
+	URL   string `json:"url"`
+	SubID int64  `json:"sub_id,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// ImportURLResponse Import summary with per-line results
+type ImportURLResponse struct {
+	Created int                `json:"created"`
+	Failed  int                `json:"failed"`
+	Results []ImportLineResult `json:"results"`
+}
+
+// ImportSubsFromURL godoc
+// @Summary 从远程列表导入订阅
+// @Description 获取每行一个订阅URL的远程列表并逐行创建订阅，返回每行的导入结果，空行和#开头的行将被忽略
+// @Tags 订阅
+// @Accept json
+// @Produce json
+// @Param request body ImportURLRequest true "导入列表"
+// @Success 200 {object} model.SuccessResponse{data=ImportURLResponse} "订阅已导入"
+// @Failure 400 {object} model.BadRequestResponse{} "无效请求"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 502 {object} model.StandardResponse{} "获取导入列表失败"
+// @Router /api/sub/import-url [post]
+// @Security BearerAuth
+func (h *SubHandler) ImportSubsFromURL(c *gin.Context) {
+	var req ImportURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request data",
+			Data:    nil,
+		})
+		return
+	}
+
+	if err := validator.ValidateSubURL(req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request data",
+			Data:    nil,
+		})
+		return
+	}
+
+	if req.Cron == "" {
+		req.Cron = h.config.Scheduler.DefaultCron
+	}
+
+	if err := validator.ValidateCron(req.Cron); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid cron expression: " + err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+
+	list, err := h.subFetcher.FetchURL(c.Request.Context(), req.URL)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, model.StandardResponse{
+			Code:    http.StatusBadGateway,
+			Message: "Failed to fetch import list",
+			Data:    nil,
+		})
+		logger.Error("Failed to fetch import list: %v, URL: %s", err, req.URL)
+		return
+	}
+
+	resp := ImportURLResponse{Results: make([]ImportLineResult, 0)}
+	for i, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if len(resp.Results) >= maxImportLines {
+			logger.Warn("Import list %s has more than %d URLs, the rest is ignored", req.URL, maxImportLines)
+			break
+		}
+
+		result := ImportLineResult{Line: i + 1, URL: line}
+		if sub, err := h.importSub(c, line, req.Cron, req.AutoUpdate); err != nil {
+			result.Error = err.Error()
+			resp.Failed++
+		} else {
+			result.SubID = sub.ID
+			resp.Created++
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	if len(resp.Results) == 0 {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Import list contains no URLs",
+			Data:    nil,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Subscriptions imported",
+		Data:    resp,
+	})
+}
+
+// importSub Validates and creates a single imported subscription
+func (h *SubHandler) importSub(c *gin.Context, subURL, cron string, autoUpdate bool) (*model.Sub, error) {
+	if err := validator.ValidateSubURL(subURL); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := requestContext(c, h.config, RequestTimeout)
+	defer cancel()
+
+	sub := &model.Sub{
+		URL:        subURL,
+		Cron:       cron,
+		AutoUpdate: autoUpdate,
+	}
+	if err := h.subRepo.Create(ctx, sub); err != nil {
+		if !errors.Is(err, model.ErrSubExists) {
+			logger.Error("Failed to create imported subscription: %v, URL: %s", err, subURL)
+		}
+		return nil, err
+	}
+
+	h.auditSvc.Record(c.GetInt64("user_id"), model.AuditSubCreate, fmt.Sprintf("sub:%d", sub.ID))
+	return sub, nil
+}
+
+// SubScheduleEntry Schedule of one auto-update subscription
+type SubScheduleEntry struct {
+	ID     int64  `json:"id"`
+	URL    string `json:"url"`
+	Remark string `json:"remark,omitempty"`
+	Cron   string `json:"cron"`
+	// LastRun Time of the last successful fetch
+	LastRun *time.Time `json:"last_run,omitempty"`
+	// NextRun Next time the cron fires, null when the stored cron is invalid
+	NextRun *time.Time `json:"next_run"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// GetSubSchedule godoc
+// @Summary 获取订阅更新计划
+// @Description 返回所有自动更新订阅的cron、上次运行时间以及根据cron计算的下次运行时间，按下次运行时间排序
+// @Tags 订阅
+// @Produce json
+// @Success 200 {object} model.SuccessResponse{data=[]SubScheduleEntry} "成功"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
+// @Router /api/sub/schedule [get]
+// @Security BearerAuth
+func (h *SubHandler) GetSubSchedule(c *gin.Context) {
+	ctx, cancel := requestContext(c, h.config, RequestTimeout)
+	defer cancel()
+
+	subs, err := h.subRepo.GetAllAutoUpdateSubs(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve subscriptions",
+			Data:    nil,
+		})
+		logger.Error("Failed to get auto-update subscriptions: %v", err)
+		return
+	}
+
+	// Computed on every request from the stored crons, so edits are reflected immediately
+	now := time.Now()
+	entries := make([]SubScheduleEntry, 0, len(subs))
+	for _, sub := range subs {
+		entry := SubScheduleEntry{
+			ID:      sub.ID,
+			URL:     sub.URL,
+			Remark:  sub.Remark,
+			Cron:    sub.Cron,
+			LastRun: sub.LastFetch,
+		}
+		if runs, err := validator.NextRuns(sub.Cron, now, 1); err != nil {
+			entry.Error = err.Error()
+		} else if len(runs) > 0 {
+			entry.NextRun = &runs[0]
+		}
+		entries = append(entries, entry)
+	}
+
+	// Soonest first, subs without a next run last
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].NextRun, entries[j].NextRun
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.Before(*b)
+	})
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    entries,
+	})
+}
@@ -0,0 +1,737 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/repository"
+	"github.com/bestruirui/bestsub/internal/service"
+)
+
+// createTestSubs Creates one sub per URL directly in the repository
+func createTestSubs(t *testing.T, urls ...string) []*model.Sub {
+	t.Helper()
+
+	repo := repository.NewSubRepository(database.DB)
+	subs := make([]*model.Sub, 0, len(urls))
+	for _, u := range urls {
+		sub := &model.Sub{URL: u, Cron: "0 */1 * * *"}
+		if err := repo.Create(context.Background(), sub); err != nil {
+			t.Fatalf("failed to create sub %s: %v", u, err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// listSubIDs Returns the sub IDs of GET /api/sub/list in response order
+func listSubIDs(t *testing.T, engine http.Handler, token, query string) []int64 {
+	t.Helper()
+
+	w := doRequest(t, engine, http.MethodGet, "/api/sub/list"+query, token, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("list status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	resp := decodeResponse[[]model.Sub](t, w)
+	ids := make([]int64, len(resp.Data))
+	for i, sub := range resp.Data {
+		ids[i] = sub.ID
+	}
+	return ids
+}
+
+func TestReorderSubs(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	subs := createTestSubs(t, "http://a.example/sub", "http://b.example/sub", "http://c.example/sub")
+	a, b, c := subs[0].ID, subs[1].ID, subs[2].ID
+
+	if got, want := listSubIDs(t, engine, token, ""), []int64{a, b, c}; !slices.Equal(got, want) {
+		t.Fatalf("initial order = %v, want %v", got, want)
+	}
+
+	w := doRequest(t, engine, http.MethodPost, "/api/sub/reorder", token, map[string]any{"ids": []int64{b, c, a}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("reorder status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	if got, want := listSubIDs(t, engine, token, ""), []int64{b, c, a}; !slices.Equal(got, want) {
+		t.Errorf("order after reorder = %v, want %v", got, want)
+	}
+}
+
+func TestReorderSubsRejectsInvalidOrder(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	subs := createTestSubs(t, "http://a.example/sub", "http://b.example/sub")
+
+	tests := []struct {
+		name string
+		ids  []int64
+		want int
+	}{
+		{"duplicate", []int64{subs[0].ID, subs[0].ID}, http.StatusBadRequest},
+		{"unknown", []int64{subs[0].ID, subs[1].ID + 100}, http.StatusNotFound},
+		{"empty", []int64{}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doRequest(t, engine, http.MethodPost, "/api/sub/reorder", token, map[string]any{"ids": tt.ids})
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	if got, want := listSubIDs(t, engine, token, ""), []int64{subs[0].ID, subs[1].ID}; !slices.Equal(got, want) {
+		t.Errorf("order = %v, want unchanged %v", got, want)
+	}
+}
+
+func TestGetAllSubsMinAlive(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	repo := repository.NewSubRepository(database.DB)
+	subs := createTestSubs(t, "http://a.example/sub", "http://b.example/sub", "http://c.example/sub")
+	for i, alive := range []int{1, 4, 8} {
+		if err := repo.UpdateStats(context.Background(), subs[i].ID, 10, alive); err != nil {
+			t.Fatalf("UpdateStats error = %v", err)
+		}
+	}
+
+	if got, want := listSubIDs(t, engine, token, "?min_alive=4"), []int64{subs[1].ID, subs[2].ID}; !slices.Equal(got, want) {
+		t.Errorf("min_alive=4 = %v, want %v", got, want)
+	}
+	if got := listSubIDs(t, engine, token, "?min_alive=9"); len(got) != 0 {
+		t.Errorf("min_alive=9 = %v, want none", got)
+	}
+
+	for _, value := range []string{"-1", "abc"} {
+		w := doRequest(t, engine, http.MethodGet, "/api/sub/list?min_alive="+value, token, nil)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("min_alive=%s status = %d, want 400", value, w.Code)
+		}
+	}
+}
+
+// countSubs Returns the number of rows in the subs table
+func countSubs(t *testing.T) int {
+	t.Helper()
+
+	var n int
+	if err := database.DB.QueryRow("SELECT COUNT(*) FROM subs").Scan(&n); err != nil {
+		t.Fatalf("failed to count subs: %v", err)
+	}
+	return n
+}
+
+func TestCreateSubIdempotencyKey(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	body := map[string]any{"url": "http://a.example/sub", "auto_update": true}
+
+	first := doRequest(t, engine, http.MethodPost, "/api/sub/add", token, body, "Idempotency-Key", "abc")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status = %d, want 201: %s", first.Code, first.Body.String())
+	}
+	second := doRequest(t, engine, http.MethodPost, "/api/sub/add", token, body, "Idempotency-Key", "abc")
+	if second.Code != http.StatusCreated {
+		t.Fatalf("replay status = %d, want 201: %s", second.Code, second.Body.String())
+	}
+
+	if a, b := decodeResponse[model.Sub](t, first).Data.ID, decodeResponse[model.Sub](t, second).Data.ID; a != b {
+		t.Errorf("replay returned sub %d, want %d", b, a)
+	}
+	if n := countSubs(t); n != 1 {
+		t.Errorf("subs = %d, want 1", n)
+	}
+
+	// Without the key the duplicate URL is rejected
+	if w := doRequest(t, engine, http.MethodPost, "/api/sub/add", token, body); w.Code != http.StatusConflict {
+		t.Errorf("status without key = %d, want 409", w.Code)
+	}
+}
+
+func TestCreateSubIdempotencyKeyConcurrent(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	const requests = 8
+	codes := make([]int, requests)
+	ids := make([]int64, requests)
+
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// The URL differs per request, only the key makes them the same create
+			body := map[string]any{"url": fmt.Sprintf("http://a.example/sub?n=%d", i), "auto_update": true}
+			w := doRequest(t, engine, http.MethodPost, "/api/sub/add", token, body, "Idempotency-Key", "same")
+			codes[i] = w.Code
+			if w.Code == http.StatusCreated {
+				ids[i] = decodeResponse[model.Sub](t, w).Data.ID
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i, code := range codes {
+		if code != http.StatusCreated {
+			t.Errorf("request %d status = %d, want 201", i, code)
+		}
+		if ids[i] != ids[0] {
+			t.Errorf("request %d got sub %d, want %d", i, ids[i], ids[0])
+		}
+	}
+	if n := countSubs(t); n != 1 {
+		t.Errorf("subs = %d, want 1", n)
+	}
+}
+
+func TestCreateSubIdempotencyKeyAfterFailure(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	// A rejected request does not consume the key
+	bad := map[string]any{"url": "http://a.example/sub", "auto_update": true, "cron": "bad"}
+	if w := doRequest(t, engine, http.MethodPost, "/api/sub/add", token, bad, "Idempotency-Key", "retry"); w.Code != http.StatusBadRequest {
+		t.Fatalf("invalid request status = %d, want 400", w.Code)
+	}
+
+	good := map[string]any{"url": "http://a.example/sub", "auto_update": true}
+	if w := doRequest(t, engine, http.MethodPost, "/api/sub/add", token, good, "Idempotency-Key", "retry"); w.Code != http.StatusCreated {
+		t.Fatalf("retry status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+	if n := countSubs(t); n != 1 {
+		t.Errorf("subs = %d, want 1", n)
+	}
+}
+
+func TestCreateSubDefaultCron(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	cfg.Scheduler.DefaultCron = "*/30 * * * *"
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	w := doRequest(t, engine, http.MethodPost, "/api/sub/add", token, map[string]any{"url": "http://a.example/sub", "auto_update": true})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+	created := decodeResponse[model.Sub](t, w).Data
+	if created.Cron != cfg.Scheduler.DefaultCron {
+		t.Errorf("response cron = %q, want %q", created.Cron, cfg.Scheduler.DefaultCron)
+	}
+
+	stored, err := repository.NewSubRepository(database.DB).GetByID(context.Background(), created.ID)
+	if err != nil {
+		t.Fatalf("GetByID error = %v", err)
+	}
+	if stored.Cron != cfg.Scheduler.DefaultCron {
+		t.Errorf("stored cron = %q, want %q", stored.Cron, cfg.Scheduler.DefaultCron)
+	}
+
+	// An explicit cron is still validated
+	w = doRequest(t, engine, http.MethodPost, "/api/sub/add", token, map[string]any{"url": "http://b.example/sub", "auto_update": true, "cron": "* * *"})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status with invalid cron = %d, want 400", w.Code)
+	}
+}
+
+func TestSubURLVarsMasked(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	w := doRequest(t, engine, http.MethodPost, "/api/sub/add", token, map[string]any{
+		"url":         "http://a.example/sub?token={{.Token}}",
+		"url_vars":    map[string]string{"Token": "secret-token"},
+		"auto_update": true,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+	id := decodeResponse[model.Sub](t, w).Data.ID
+
+	detailPath := fmt.Sprintf("/api/sub/%d", id)
+	for _, path := range []string{detailPath, "/api/sub/list"} {
+		w := doRequest(t, engine, http.MethodGet, path, token, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s status = %d, want 200", path, w.Code)
+		}
+		body := w.Body.String()
+		if strings.Contains(body, "secret-token") {
+			t.Errorf("%s response contains the variable value: %s", path, body)
+		}
+		if !strings.Contains(body, `"url_vars":{"Token":"***"}`) {
+			t.Errorf("%s response does not list the masked variable: %s", path, body)
+		}
+	}
+
+	// Sending the masked value back keeps the stored secret
+	w = doRequest(t, engine, http.MethodPatch, detailPath, token, map[string]any{
+		"url_vars": map[string]string{"Token": model.RedactedURLVar, "Region": "eu"},
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("patch status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	stored, err := repository.NewSubRepository(database.DB).GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetByID error = %v", err)
+	}
+	if stored.URLVars["Token"] != "secret-token" || stored.URLVars["Region"] != "eu" {
+		t.Errorf("stored vars = %v, want the original token and the new region", stored.URLVars)
+	}
+}
+
+func TestCreateSubRejectsNegativeFetchTimeout(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	w := doRequest(t, engine, http.MethodPost, "/api/sub/add", token, map[string]any{
+		"url":                   "http://a.example/sub",
+		"auto_update":           true,
+		"fetch_timeout_seconds": -1,
+	})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+	if n := countSubs(t); n != 0 {
+		t.Errorf("subs = %d, want 0", n)
+	}
+}
+
+func TestPatchSubAppliesOnlyPresentFields(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+	repo := repository.NewSubRepository(database.DB)
+
+	sub := &model.Sub{URL: "http://a.example/sub", Cron: "0 */1 * * *", AutoUpdate: true, Remark: "primary"}
+	if err := repo.Create(context.Background(), sub); err != nil {
+		t.Fatalf("failed to create sub: %v", err)
+	}
+	path := fmt.Sprintf("/api/sub/%d", sub.ID)
+
+	patch := func(body string) *model.Sub {
+		t.Helper()
+		w := doRequest(t, engine, http.MethodPatch, path, token, body)
+		if w.Code != http.StatusOK {
+			t.Fatalf("patch %s status = %d, want 200: %s", body, w.Code, w.Body.String())
+		}
+		stored, err := repo.GetByID(context.Background(), sub.ID)
+		if err != nil {
+			t.Fatalf("GetByID error = %v", err)
+		}
+		return stored
+	}
+
+	got := patch(`{"cron":"*/5 * * * *"}`)
+	if got.Cron != "*/5 * * * *" || !got.AutoUpdate || got.URL != sub.URL || got.Remark != sub.Remark {
+		t.Errorf("after cron patch = cron %q auto_update %v url %q remark %q, want only the cron changed",
+			got.Cron, got.AutoUpdate, got.URL, got.Remark)
+	}
+
+	got = patch(`{"auto_update":false}`)
+	if got.AutoUpdate || got.Cron != "*/5 * * * *" || got.URL != sub.URL || got.Remark != sub.Remark {
+		t.Errorf("after auto_update patch = cron %q auto_update %v url %q remark %q, want only auto_update changed",
+			got.Cron, got.AutoUpdate, got.URL, got.Remark)
+	}
+
+	// An explicit empty remark clears it, null leaves it alone
+	if got = patch(`{"remark":null}`); got.Remark != sub.Remark {
+		t.Errorf("remark after null patch = %q, want %q", got.Remark, sub.Remark)
+	}
+	if got = patch(`{"remark":""}`); got.Remark != "" {
+		t.Errorf("remark after empty patch = %q, want empty", got.Remark)
+	}
+
+	if w := doRequest(t, engine, http.MethodPatch, path, token, `{"cron":"* * *"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid cron patch status = %d, want 400", w.Code)
+	}
+}
+
+func TestGetAllSubsCursorPaging(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	var want []int64
+	for i := 0; i < 7; i++ {
+		want = append(want, createTestSubs(t, fmt.Sprintf("http://%d.example/sub", i))[0].ID)
+	}
+
+	var got []int64
+	seen := make(map[int64]bool)
+	query := "?limit=3"
+	for pages := 0; ; pages++ {
+		if pages > len(want) {
+			t.Fatal("paging did not terminate")
+		}
+
+		w := doRequest(t, engine, http.MethodGet, "/api/sub/list"+query, token, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+		page := decodeResponse[SubPage](t, w).Data
+		if len(page.Items) > 3 {
+			t.Fatalf("page has %d items, want at most 3", len(page.Items))
+		}
+		for _, sub := range page.Items {
+			if seen[sub.ID] {
+				t.Errorf("sub %d returned twice", sub.ID)
+			}
+			seen[sub.ID] = true
+			got = append(got, sub.ID)
+		}
+
+		if page.NextCursor == nil {
+			break
+		}
+		query = fmt.Sprintf("?limit=3&after_id=%d", *page.NextCursor)
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("paged IDs = %v, want %v", got, want)
+	}
+
+	// Without cursor parameters the plain list is returned
+	if ids := listSubIDs(t, engine, token, ""); len(ids) != len(want) {
+		t.Errorf("unpaged list has %d subs, want %d", len(ids), len(want))
+	}
+
+	for _, query := range []string{"?limit=0", "?limit=101", "?after_id=-1", "?after_id=abc"} {
+		if w := doRequest(t, engine, http.MethodGet, "/api/sub/list"+query, token, nil); w.Code != http.StatusBadRequest {
+			t.Errorf("%s status = %d, want 400", query, w.Code)
+		}
+	}
+}
+
+func TestSubRemarkPersists(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	w := doRequest(t, engine, http.MethodPost, "/api/sub/add", token, map[string]any{"url": "http://a.example/sub", "auto_update": true, "remark": "work VPN"})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+	created := decodeResponse[model.Sub](t, w).Data
+	if created.Remark != "work VPN" {
+		t.Errorf("response remark = %q, want %q", created.Remark, "work VPN")
+	}
+	path := fmt.Sprintf("/api/sub/%d", created.ID)
+
+	w = doRequest(t, engine, http.MethodGet, path, token, nil)
+	if got := decodeResponse[model.Sub](t, w).Data.Remark; got != "work VPN" {
+		t.Errorf("detail remark = %q, want %q", got, "work VPN")
+	}
+
+	w = doRequest(t, engine, http.MethodGet, "/api/sub/list", token, nil)
+	if list := decodeResponse[[]model.Sub](t, w).Data; len(list) != 1 || list[0].Remark != "work VPN" {
+		t.Errorf("list = %+v, want one sub with the remark", list)
+	}
+
+	w = doRequest(t, engine, http.MethodPut, path, token, map[string]any{"url": "http://a.example/sub", "remark": "backup provider"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("update status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	stored, err := repository.NewSubRepository(database.DB).GetByID(context.Background(), created.ID)
+	if err != nil {
+		t.Fatalf("GetByID error = %v", err)
+	}
+	if stored.Remark != "backup provider" {
+		t.Errorf("stored remark = %q, want %q", stored.Remark, "backup provider")
+	}
+
+	w = doRequest(t, engine, http.MethodPatch, path, token, map[string]any{"remark": strings.Repeat("x", 257)})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("over-long remark status = %d, want 400", w.Code)
+	}
+}
+
+func TestImportSubsFromURL(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	list := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "http://a.example/sub\n\n# backup providers\nhttps://b.example/sub\r\nhttp://c.example/sub\nftp://d.example/sub\nhttp://a.example/sub\n")
+	}))
+	t.Cleanup(list.Close)
+
+	w := doRequest(t, engine, http.MethodPost, "/api/sub/import-url", token, ImportURLRequest{URL: list.URL, AutoUpdate: true})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	resp := decodeResponse[ImportURLResponse](t, w).Data
+	if resp.Created != 3 || resp.Failed != 2 {
+		t.Errorf("created = %d, failed = %d, want 3 and 2", resp.Created, resp.Failed)
+	}
+
+	wantLines := []int{1, 4, 5, 6, 7}
+	if len(resp.Results) != len(wantLines) {
+		t.Fatalf("results = %+v, want %d entries", resp.Results, len(wantLines))
+	}
+	for i, result := range resp.Results {
+		if result.Line != wantLines[i] {
+			t.Errorf("result %d line = %d, want %d", i, result.Line, wantLines[i])
+		}
+		failed := i >= 3
+		if failed != (result.Error != "") || failed != (result.SubID == 0) {
+			t.Errorf("result %d = %+v, want failed = %v", i, result, failed)
+		}
+	}
+
+	if n := countSubs(t); n != 3 {
+		t.Errorf("subs = %d, want 3", n)
+	}
+	stored, err := repository.NewSubRepository(database.DB).GetByID(context.Background(), resp.Results[1].SubID)
+	if err != nil {
+		t.Fatalf("GetByID error = %v", err)
+	}
+	if stored.URL != "https://b.example/sub" || stored.Cron != cfg.Scheduler.DefaultCron || !stored.AutoUpdate {
+		t.Errorf("imported sub = url %q cron %q auto_update %v, want the listed URL with the defaults",
+			stored.URL, stored.Cron, stored.AutoUpdate)
+	}
+
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "# nothing here\n")
+	}))
+	t.Cleanup(empty.Close)
+	if w := doRequest(t, engine, http.MethodPost, "/api/sub/import-url", token, ImportURLRequest{URL: empty.URL}); w.Code != http.StatusBadRequest {
+		t.Errorf("empty list status = %d, want 400", w.Code)
+	}
+}
+
+func TestCreateSubFetchMethod(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	w := doRequest(t, engine, http.MethodPost, "/api/sub/add", token, map[string]any{
+		"url": "http://a.example/sub", "auto_update": true, "fetch_method": "POST", "fetch_body": "token=abc",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201: %s", w.Code, w.Body.String())
+	}
+	created := decodeResponse[model.Sub](t, w).Data
+	if created.FetchMethod != http.MethodPost || created.FetchBody != "token=abc" {
+		t.Errorf("fetch method = %q, body = %q, want POST with the body", created.FetchMethod, created.FetchBody)
+	}
+
+	for _, method := range []string{"PUT", "post"} {
+		w = doRequest(t, engine, http.MethodPost, "/api/sub/add", token, map[string]any{
+			"url": "http://b.example/sub", "auto_update": true, "fetch_method": method,
+		})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("fetch method %q status = %d, want 400", method, w.Code)
+		}
+	}
+}
+
+func TestFetchErrorStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"timeout", &model.FetchError{Kind: model.FetchErrorTimeout, Err: context.DeadlineExceeded}, http.StatusGatewayTimeout},
+		{"dns", &model.FetchError{Kind: model.FetchErrorDNS, Err: errors.New("no such host")}, http.StatusBadGateway},
+		{"status", &model.FetchError{Kind: model.FetchErrorStatus, StatusCode: http.StatusForbidden}, http.StatusBadGateway},
+		{"body", &model.FetchError{Kind: model.FetchErrorBody, Err: errors.New("unexpected EOF")}, http.StatusBadGateway},
+		{"connect", &model.FetchError{Kind: model.FetchErrorConnect, Err: errors.New("connection refused")}, http.StatusBadGateway},
+		{"wrapped", fmt.Errorf("failed to fetch content: %w", &model.FetchError{Kind: model.FetchErrorTimeout}), http.StatusGatewayTimeout},
+		{"other", errors.New("failed to store content"), http.StatusServiceUnavailable},
+	}
+
+	messages := make(map[string]bool)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, message := fetchErrorStatus(tt.err)
+			if status != tt.want {
+				t.Errorf("status = %d, want %d", status, tt.want)
+			}
+			if message == "" {
+				t.Error("message is empty")
+			}
+			messages[message] = true
+		})
+	}
+
+	// Every category reports its own message, the wrapped timeout shares the timeout one
+	if len(messages) != len(tests)-1 {
+		t.Errorf("got %d distinct messages, want %d", len(messages), len(tests)-1)
+	}
+}
+
+func TestGetSubSchedule(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+	repo := repository.NewSubRepository(database.DB)
+
+	create := func(url, cron string, autoUpdate bool) *model.Sub {
+		t.Helper()
+		sub := &model.Sub{URL: url, Cron: cron, AutoUpdate: autoUpdate}
+		if err := repo.Create(context.Background(), sub); err != nil {
+			t.Fatalf("failed to create sub %s: %v", url, err)
+		}
+		return sub
+	}
+	// Half a day away, so the daily run never competes with the 5 minute one
+	dailyHour := (time.Now().Hour() + 12) % 24
+	daily := create("http://daily.example/sub", fmt.Sprintf("0 %d * * *", dailyHour), true)
+	invalid := create("http://invalid.example/sub", "61 * * * *", true)
+	frequent := create("http://frequent.example/sub", "*/5 * * * *", true)
+	never := create("http://never.example/sub", "0 0 30 2 *", true)
+	create("http://manual.example/sub", "*/1 * * * *", false)
+
+	before := time.Now().Truncate(time.Minute)
+	w := doRequest(t, engine, http.MethodGet, "/api/sub/schedule", token, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	entries := decodeResponse[[]SubScheduleEntry](t, w).Data
+
+	ids := make([]int64, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+	}
+	if want := []int64{frequent.ID, daily.ID, invalid.ID, never.ID}; !slices.Equal(ids, want) {
+		t.Fatalf("schedule order = %v, want %v", ids, want)
+	}
+
+	if next := entries[0].NextRun; next == nil || !next.After(before) || next.Sub(before) > 5*time.Minute || next.Minute()%5 != 0 {
+		t.Errorf("next run of */5 = %v, want the next 5 minute boundary after %v", next, before)
+	}
+	if next := entries[1].NextRun; next == nil || next.Local().Hour() != dailyHour || next.Minute() != 0 || next.Sub(before) > 24*time.Hour {
+		t.Errorf("next run of daily %02d:00 = %v, want it within a day", dailyHour, next)
+	}
+	if entries[2].NextRun != nil || entries[2].Error == "" {
+		t.Errorf("invalid cron entry = %+v, want no next run and an error", entries[2])
+	}
+	if entries[3].NextRun != nil || entries[3].Error != "" {
+		t.Errorf("February 30th entry = %+v, want no next run and no error", entries[3])
+	}
+}
+
+func TestUpdateSubStats(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	sub := createTestSubs(t, "http://a.example/sub")[0]
+	path := fmt.Sprintf("/api/sub/%d/stats", sub.ID)
+
+	w := doRequest(t, engine, http.MethodPut, path, token, map[string]int{"total_nodes": 12, "alive_nodes": 9})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	w = doRequest(t, engine, http.MethodGet, fmt.Sprintf("/api/sub/%d", sub.ID), token, nil)
+	if detail := decodeResponse[model.Sub](t, w).Data; detail.TotalNodes != 12 || detail.AliveNodes != 9 {
+		t.Errorf("detail stats = %d/%d, want 9/12 alive", detail.AliveNodes, detail.TotalNodes)
+	}
+
+	tests := []struct {
+		name string
+		body any
+		path string
+		want int
+	}{
+		{"alive over total", map[string]int{"total_nodes": 3, "alive_nodes": 4}, path, http.StatusBadRequest},
+		{"negative", map[string]int{"total_nodes": -1, "alive_nodes": 0}, path, http.StatusBadRequest},
+		{"missing field", map[string]int{"total_nodes": 3}, path, http.StatusBadRequest},
+		{"unknown sub", map[string]int{"total_nodes": 3, "alive_nodes": 1}, fmt.Sprintf("/api/sub/%d/stats", sub.ID+1000), http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := doRequest(t, engine, http.MethodPut, tt.path, token, tt.body); w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	// Rejected updates leave the stored stats alone
+	stored, err := repository.NewSubRepository(database.DB).GetByID(context.Background(), sub.ID)
+	if err != nil {
+		t.Fatalf("GetByID error = %v", err)
+	}
+	if stored.TotalNodes != 12 || stored.AliveNodes != 9 {
+		t.Errorf("stored stats = %d/%d, want 9/12 alive", stored.AliveNodes, stored.TotalNodes)
+	}
+}
+
+func TestPinSubPersistsContent(t *testing.T) {
+	resetTables(t)
+	service.ClearAllContent()
+	t.Cleanup(service.ClearAllContent)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+	repo := repository.NewSubRepository(database.DB)
+	sub := createTestSubs(t, "http://a.example/sub")[0]
+	pinPath := fmt.Sprintf("/api/sub/%d/pin", sub.ID)
+
+	if w := doRequest(t, engine, http.MethodPost, pinPath, token, nil); w.Code != http.StatusNotFound {
+		t.Errorf("pin without content status = %d, want 404: %s", w.Code, w.Body.String())
+	}
+
+	if err := service.StoreSubContent(sub.ID, "vmess://good"); err != nil {
+		t.Fatalf("StoreSubContent error = %v", err)
+	}
+	if w := doRequest(t, engine, http.MethodPost, pinPath, token, nil); w.Code != http.StatusOK {
+		t.Fatalf("pin status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if content, ok, err := repo.GetPinnedContent(context.Background(), sub.ID); err != nil || !ok || content != "vmess://good" {
+		t.Errorf("pinned copy = %q, %v, %v, want the pinned content", content, ok, err)
+	}
+
+	if w := doRequest(t, engine, http.MethodPost, fmt.Sprintf("/api/sub/%d/unpin", sub.ID), token, nil); w.Code != http.StatusOK {
+		t.Fatalf("unpin status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if _, ok, err := repo.GetPinnedContent(context.Background(), sub.ID); err != nil || ok {
+		t.Errorf("pinned copy after unpin = %v, %v, want none", ok, err)
+	}
+}
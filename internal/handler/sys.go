@@ -1,42 +1,71 @@
 package handler
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"io/fs"
 	"net/http"
 	"path"
 	"strings"
 	"time"
 
+	"github.com/bestruirui/bestsub/internal/config"
+	"github.com/bestruirui/bestsub/internal/database"
 	"github.com/bestruirui/bestsub/internal/logger"
+	"github.com/bestruirui/bestsub/internal/middleware"
 	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/repository"
 	"github.com/bestruirui/bestsub/internal/router"
+	"github.com/bestruirui/bestsub/internal/service"
+	"github.com/bestruirui/bestsub/internal/validator"
 	"github.com/bestruirui/bestsub/web"
 	"github.com/gin-gonic/gin"
 )
 
 // SystemHandler
 type SystemHandler struct {
-	config *model.Config
-	fsRoot fs.FS
+	db       *sql.DB
+	auditSvc *service.AuditService
+	subRepo  repository.SubRepository
+	config   *model.Config
+	fsRoot   fs.FS
 }
 
 // NewSystemHandler Creates system handler instance
-func NewSystemHandler(config *model.Config) *SystemHandler {
+func NewSystemHandler(db *sql.DB, config *model.Config) *SystemHandler {
+	h := &SystemHandler{
+		db:       db,
+		auditSvc: service.NewAuditService(repository.NewAuditRepository(db)),
+		subRepo:  repository.NewSubRepository(db),
+		config:   config,
+	}
+
+	if config.Server.APIOnly {
+		return h
+	}
+
 	subFS, err := fs.Sub(web.Web, "out")
 	if err != nil {
-		logger.Error("Failed to get sub filesystem: %v", err)
+		logger.Warn("Web UI assets unavailable (%v), running in API-only mode", err)
+		return h
 	}
 
-	return &SystemHandler{
-		config: config,
-		fsRoot: subFS,
+	if _, err := fs.Stat(subFS, "index.html"); err != nil {
+		logger.Warn("Web UI assets not bundled in this build, running in API-only mode")
+		return h
 	}
+
+	h.fsRoot = subFS
+	return h
 }
 
 // Groups Returns all route group configurations
 func (h *SystemHandler) Groups() []*router.GroupRouter {
 	return []*router.GroupRouter{
 		h.SystemGroup(),
+		h.SystemAPIGroup(),
 	}
 }
 
@@ -47,27 +76,617 @@ func (h *SystemHandler) SystemGroup() *router.GroupRouter {
 			router.NewRoute("/health", router.GET).
 				Handle(h.HealthCheck).
 				WithDescription("Health check endpoint"),
+		).
+		AddRoute(
+			router.NewRoute("/health/live", router.GET).
+				Handle(h.LivenessCheck).
+				WithDescription("Liveness probe"),
+		).
+		AddRoute(
+			router.NewRoute("/health/ready", router.GET).
+				Handle(h.ReadinessCheck).
+				WithDescription("Readiness probe"),
 		)
 }
 
+// SystemAPIGroup Returns authenticated system API route group
+func (h *SystemHandler) SystemAPIGroup() *router.GroupRouter {
+	return router.NewGroupRouter("/api/system").
+		Use(middleware.JWTAuth(h.config), middleware.RouteTimeout(h.config, "/api/system")).
+		AddRoute(
+			router.NewRoute("/validate-cron", router.POST).
+				Handle(h.ValidateCron).
+				WithDescription("Validate cron expression"),
+		).
+		AddRoute(
+			router.NewRoute("/jwt/rotate", router.POST).
+				Use(middleware.AdminIPAllowList(h.config), middleware.AdminOnly()).
+				Handle(h.RotateJWTSecret).
+				WithDescription("Rotate JWT secret"),
+		).
+		AddRoute(
+			router.NewRoute("/maintenance", router.GET).
+				Handle(h.GetMaintenance).
+				WithDescription("Get maintenance mode"),
+		).
+		AddRoute(
+			router.NewRoute("/maintenance", router.PUT).
+				Use(middleware.AdminIPAllowList(h.config), middleware.AdminOnly()).
+				Handle(h.SetMaintenance).
+				WithDescription("Set maintenance mode"),
+		).
+		AddRoute(
+			router.NewRoute("/audit", router.GET).
+				Use(middleware.AdminIPAllowList(h.config), middleware.AdminOnly()).
+				Handle(h.ListAuditLogs).
+				WithDescription("List audit logs"),
+		).
+		AddRoute(
+			router.NewRoute("/migrate", router.POST).
+				Use(middleware.AdminIPAllowList(h.config), middleware.AdminOnly()).
+				Handle(h.MigrateDatabase).
+				WithDescription("Apply migrations up to a version"),
+		).
+		AddRoute(
+			router.NewRoute("/db-stats", router.GET).
+				Use(middleware.AdminIPAllowList(h.config), middleware.AdminOnly()).
+				Handle(h.GetDBStats).
+				WithDescription("Get database connection pool statistics"),
+		).
+		AddRoute(
+			router.NewRoute("/db-stats", router.PUT).
+				Use(middleware.AdminIPAllowList(h.config), middleware.AdminOnly()).
+				Handle(h.SetDBPool).
+				WithDescription("Tune database connection pool"),
+		).
+		AddRoute(
+			router.NewRoute("/config", router.GET).
+				Use(middleware.AdminIPAllowList(h.config), middleware.AdminOnly()).
+				Handle(h.GetConfig).
+				WithDescription("Get sanitized running config"),
+		).
+		AddRoute(
+			router.NewRoute("/stats", router.GET).
+				Handle(h.GetStats).
+				WithDescription("Get runtime statistics"),
+		)
+}
+
+// HealthStatus Health check data
+type HealthStatus struct {
+	Status string `json:"status" example:"ok"`
+	Time   string `json:"time" example:"2024-01-01T00:00:00Z"`
+}
+
 // HealthCheck godoc
 // @Summary 健康检查
-// @Description 获取服务器健康状态
+// @Description 获取服务器健康状态，format=standard时使用统一响应结构，否则返回兼容旧版的扁平结构
 // @Tags 系统
 // @Produce json
-// @Success 200 {object} object{status=string,time=string} "服务器健康"
+// @Param format query string false "响应格式" Enums(standard)
+// @Success 200 {object} HealthStatus "服务器健康"
 // @Router /api/health [get]
 func (h *SystemHandler) HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status": "ok",
-		"time":   time.Now().Format(time.RFC3339),
+	status := HealthStatus{
+		Status: "ok",
+		Time:   time.Now().Format(time.RFC3339),
+	}
+
+	if c.Query("format") == "standard" {
+		c.JSON(http.StatusOK, model.SuccessResponse{
+			Code:    http.StatusOK,
+			Message: "Success",
+			Data:    status,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// LivenessCheck godoc
+// @Summary 存活探针
+// @Description 进程能够响应请求即返回200
+// @Tags 系统
+// @Produce json
+// @Success 200 {object} HealthStatus "进程存活"
+// @Router /api/health/live [get]
+func (h *SystemHandler) LivenessCheck(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthStatus{
+		Status: "ok",
+		Time:   time.Now().Format(time.RFC3339),
+	})
+}
+
+// ReadinessStatus Readiness probe result with the outcome of each dependency check
+type ReadinessStatus struct {
+	Status string            `json:"status" example:"ok"`
+	Time   string            `json:"time" example:"2024-01-01T00:00:00Z"`
+	Checks map[string]string `json:"checks"`
+}
+
+// ReadinessCheck godoc
+// @Summary 就绪探针
+// @Description 检查数据库是否可用以及是否存在未应用的迁移，全部检查通过时返回200，否则返回503
+// @Tags 系统
+// @Produce json
+// @Success 200 {object} ReadinessStatus "服务就绪"
+// @Failure 503 {object} ReadinessStatus "服务未就绪"
+// @Router /api/health/ready [get]
+func (h *SystemHandler) ReadinessCheck(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Second)
+	defer cancel()
+
+	status := ReadinessStatus{
+		Status: "ok",
+		Time:   time.Now().Format(time.RFC3339),
+		Checks: map[string]string{"database": "ok"},
+	}
+	code := http.StatusOK
+
+	if err := h.db.PingContext(ctx); err != nil {
+		status.Status = "unavailable"
+		status.Checks["database"] = err.Error()
+		code = http.StatusServiceUnavailable
+		logger.Warn("Readiness check failed: database unavailable: %v", err)
+		c.JSON(code, status)
+		return
+	}
+
+	// Queries select columns added by migrations, so the service is not usable until they are applied
+	status.Checks["migrations"] = "ok"
+	pending, err := database.PendingMigrations(h.db)
+	switch {
+	case err != nil:
+		status.Status = "unavailable"
+		status.Checks["migrations"] = err.Error()
+		code = http.StatusServiceUnavailable
+		logger.Warn("Readiness check failed: cannot check migrations: %v", err)
+	case len(pending) > 0:
+		status.Status = "unavailable"
+		status.Checks["migrations"] = fmt.Sprintf("%d pending, latest version is %d", len(pending), database.LatestVersion())
+		code = http.StatusServiceUnavailable
+	}
+
+	c.JSON(code, status)
+}
+
+// cronPreviewRuns Number of upcoming fire times returned by ValidateCron
+const cronPreviewRuns = 5
+
+// ValidateCronRequest Cron validation request
+type ValidateCronRequest struct {
+	Cron string `json:"cron" binding:"required"`
+}
+
+// ValidateCronResponse Cron validation result
+type ValidateCronResponse struct {
+	Valid    bool        `json:"valid"`
+	Error    string      `json:"error,omitempty"`
+	NextRuns []time.Time `json:"next_runs"`
+}
+
+// ValidateCron godoc
+// @Summary 校验cron表达式
+// @Description 校验cron表达式并返回接下来的几次执行时间
+// @Tags 系统
+// @Accept json
+// @Produce json
+// @Param request body ValidateCronRequest true "cron表达式"
+// @Success 200 {object} model.SuccessResponse{data=ValidateCronResponse} "成功"
+// @Failure 400 {object} model.BadRequestResponse{} "无效请求"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Router /api/system/validate-cron [post]
+// @Security BearerAuth
+func (h *SystemHandler) ValidateCron(c *gin.Context) {
+	var req ValidateCronRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request data",
+			Data:    nil,
+		})
+		return
+	}
+
+	resp := ValidateCronResponse{
+		Valid:    true,
+		NextRuns: []time.Time{},
+	}
+
+	nextRuns, err := validator.NextRuns(req.Cron, time.Now(), cronPreviewRuns)
+	if err != nil {
+		resp.Valid = false
+		resp.Error = err.Error()
+	} else {
+		resp.NextRuns = nextRuns
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    resp,
+	})
+}
+
+// RotateJWTSecret godoc
+// @Summary 重置JWT密钥
+// @Description 生成新的JWT密钥并写入配置文件，所有已签发的令牌立即失效
+// @Tags 系统
+// @Produce json
+// @Success 200 {object} model.SuccessResponse{} "成功"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 403 {object} model.StandardResponse{} "需要管理员权限"
+// @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
+// @Router /api/system/jwt/rotate [post]
+// @Security BearerAuth
+func (h *SystemHandler) RotateJWTSecret(c *gin.Context) {
+	if err := config.RotateJWTSecret(h.config); err != nil {
+		c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to rotate JWT secret",
+			Data:    nil,
+		})
+		logger.Error("Failed to rotate JWT secret: %v", err)
+		return
+	}
+
+	h.auditSvc.Record(c.GetInt64("user_id"), model.AuditJWTRotate, "jwt")
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "JWT secret rotated, please log in again",
+		Data:    nil,
+	})
+}
+
+// MaintenanceStatus Maintenance mode state
+type MaintenanceStatus struct {
+	Enabled bool `json:"enabled"`
+}
+
+// SetMaintenanceRequest Maintenance mode toggle request
+type SetMaintenanceRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// GetMaintenance godoc
+// @Summary 获取维护模式状态
+// @Description 获取当前是否处于只读维护模式
+// @Tags 系统
+// @Produce json
+// @Success 200 {object} model.SuccessResponse{data=MaintenanceStatus} "成功"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Router /api/system/maintenance [get]
+// @Security BearerAuth
+func (h *SystemHandler) GetMaintenance(c *gin.Context) {
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    MaintenanceStatus{Enabled: middleware.MaintenanceMode()},
+	})
+}
+
+// SetMaintenance godoc
+// @Summary 设置维护模式
+// @Description 开启或关闭只读维护模式，开启后除登录外的写操作均返回503
+// @Tags 系统
+// @Accept json
+// @Produce json
+// @Param request body SetMaintenanceRequest true "维护模式开关"
+// @Success 200 {object} model.SuccessResponse{data=MaintenanceStatus} "成功"
+// @Failure 400 {object} model.BadRequestResponse{} "无效请求"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 403 {object} model.StandardResponse{} "需要管理员权限"
+// @Router /api/system/maintenance [put]
+// @Security BearerAuth
+func (h *SystemHandler) SetMaintenance(c *gin.Context) {
+	var req SetMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request data",
+			Data:    nil,
+		})
+		return
+	}
+
+	middleware.SetMaintenanceMode(*req.Enabled)
+	logger.Info("Maintenance mode set to %t by UserID=%d", *req.Enabled, c.GetInt64("user_id"))
+	h.auditSvc.Record(c.GetInt64("user_id"), model.AuditMaintenance, fmt.Sprintf("enabled:%t", *req.Enabled))
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Maintenance mode updated",
+		Data:    MaintenanceStatus{Enabled: *req.Enabled},
+	})
+}
+
+// Audit log pagination bounds
+const (
+	defaultAuditPageSize = 20
+	maxAuditPageSize     = 100
+)
+
+// AuditLogListResponse Paged audit log entries
+type AuditLogListResponse = model.PagedResponse[*model.AuditLog]
+
+// ListAuditLogs godoc
+// @Summary 获取审计日志
+// @Description 分页获取用户操作审计日志，按时间倒序
+// @Tags 系统
+// @Produce json
+// @Param limit query int false "每页数量，默认20，最大100"
+// @Param offset query int false "偏移量"
+// @Success 200 {object} model.SuccessResponse{data=AuditLogListResponse} "成功"
+// @Failure 400 {object} model.BadRequestResponse{} "无效请求"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 403 {object} model.StandardResponse{} "需要管理员权限"
+// @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
+// @Router /api/system/audit [get]
+// @Security BearerAuth
+func (h *SystemHandler) ListAuditLogs(c *gin.Context) {
+	ctx, cancel := requestContext(c, h.config, RequestTimeout)
+	defer cancel()
+
+	limit, offset, ok := parsePageParams(c, defaultAuditPageSize, maxAuditPageSize)
+	if !ok {
+		return
+	}
+
+	entries, total, err := h.auditSvc.List(ctx, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to retrieve audit logs",
+			Data:    nil,
+		})
+		logger.Error("Failed to list audit logs: %v", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    model.NewPagedResponse(entries, total, limit, offset),
+	})
+}
+
+// MigrateRequest Database migration request
+type MigrateRequest struct {
+	// Version Target version, migrations up to and including it are applied
+	Version int `json:"version" binding:"required,min=1" example:"2"`
+}
+
+// MigrateResponse Database migration result
+type MigrateResponse struct {
+	Applied []int `json:"applied"`
+	Version int   `json:"version"`
+}
+
+// MigrateDatabase godoc
+// @Summary 迁移数据库到指定版本
+// @Description 仅应用到指定版本为止的待执行迁移，目标版本必须大于当前版本，仅管理员可用
+// @Tags 系统
+// @Accept json
+// @Produce json
+// @Param request body MigrateRequest true "目标版本"
+// @Success 200 {object} model.SuccessResponse{data=MigrateResponse} "迁移完成"
+// @Failure 400 {object} model.BadRequestResponse{} "无效的目标版本"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 403 {object} model.StandardResponse{} "需要管理员权限"
+// @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
+// @Router /api/system/migrate [post]
+// @Security BearerAuth
+func (h *SystemHandler) MigrateDatabase(c *gin.Context) {
+	var req MigrateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request data",
+			Data:    nil,
+		})
+		return
+	}
+
+	applied, err := database.MigrateTo(h.db, req.Version)
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidMigrationTarget) {
+			c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+				Data:    nil,
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to apply migrations",
+			Data:    nil,
+		})
+		logger.Error("Failed to migrate database to version %d: %v", req.Version, err)
+		return
+	}
+
+	h.auditSvc.Record(c.GetInt64("user_id"), model.AuditMigrate, fmt.Sprintf("version:%d", req.Version))
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Migrations applied",
+		Data: MigrateResponse{
+			Applied: applied,
+			Version: req.Version,
+		},
+	})
+}
+
+// DBStats Database connection pool statistics
+type DBStats struct {
+	MaxOpenConnections int   `json:"max_open_connections"`
+	OpenConnections    int   `json:"open_connections"`
+	InUse              int   `json:"in_use"`
+	Idle               int   `json:"idle"`
+	WaitCount          int64 `json:"wait_count"`
+	// WaitDurationMs Total time spent waiting for a connection in milliseconds
+	WaitDurationMs    int64 `json:"wait_duration_ms"`
+	MaxIdleClosed     int64 `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64 `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64 `json:"max_lifetime_closed"`
+}
+
+// currentDBStats Converts the pool statistics into the response form
+func (h *SystemHandler) currentDBStats() DBStats {
+	stats := h.db.Stats()
+	return DBStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
+
+// GetDBStats godoc
+// @Summary 获取数据库连接池统计
+// @Description 获取数据库连接池的实时统计信息，仅管理员可用
+// @Tags 系统
+// @Produce json
+// @Success 200 {object} model.SuccessResponse{data=DBStats} "成功"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 403 {object} model.StandardResponse{} "需要管理员权限"
+// @Router /api/system/db-stats [get]
+// @Security BearerAuth
+func (h *SystemHandler) GetDBStats(c *gin.Context) {
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    h.currentDBStats(),
+	})
+}
+
+// SetDBPoolRequest Connection pool limits to apply, omitted fields are unchanged
+type SetDBPoolRequest struct {
+	// MaxOpenConns 0 means unlimited
+	MaxOpenConns *int `json:"max_open_conns" binding:"omitempty,min=0"`
+	// MaxIdleConns 0 disables idle connections
+	MaxIdleConns *int `json:"max_idle_conns" binding:"omitempty,min=0"`
+}
+
+// SetDBPool godoc
+// @Summary 调整数据库连接池
+// @Description 运行时调整最大打开连接数和最大空闲连接数，重启后恢复默认值，仅管理员可用
+// @Tags 系统
+// @Accept json
+// @Produce json
+// @Param request body SetDBPoolRequest true "连接池限制"
+// @Success 200 {object} model.SuccessResponse{data=DBStats} "已调整"
+// @Failure 400 {object} model.BadRequestResponse{} "无效请求"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 403 {object} model.StandardResponse{} "需要管理员权限"
+// @Router /api/system/db-stats [put]
+// @Security BearerAuth
+func (h *SystemHandler) SetDBPool(c *gin.Context) {
+	var req SetDBPoolRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+			Code:    http.StatusBadRequest,
+			Message: "Invalid request data",
+			Data:    nil,
+		})
+		return
+	}
+
+	if req.MaxOpenConns != nil {
+		h.db.SetMaxOpenConns(*req.MaxOpenConns)
+	}
+	if req.MaxIdleConns != nil {
+		h.db.SetMaxIdleConns(*req.MaxIdleConns)
+	}
+
+	logger.Info("Database pool adjusted: max_open=%d", h.db.Stats().MaxOpenConnections)
+	h.auditSvc.Record(c.GetInt64("user_id"), model.AuditDBPool, "database")
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Database pool updated",
+		Data:    h.currentDBStats(),
+	})
+}
+
+// SystemStats Runtime statistics
+type SystemStats struct {
+	ActiveFetches int64 `json:"active_fetches"`
+	// TotalContentSize Sum of the last fetched content size of all subscriptions in bytes
+	TotalContentSize int64 `json:"total_content_size"`
+}
+
+// GetConfig godoc
+// @Summary 获取运行配置
+// @Description 获取命令行参数覆盖后的当前运行配置，JWT密钥等敏感字段已脱敏，仅管理员可用
+// @Tags 系统
+// @Produce json
+// @Success 200 {object} model.SuccessResponse{data=model.Config} "成功"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 403 {object} model.StandardResponse{} "需要管理员权限"
+// @Router /api/system/config [get]
+// @Security BearerAuth
+func (h *SystemHandler) GetConfig(c *gin.Context) {
+	cfg := config.Sanitized(h.config)
+	// Maintenance mode can be toggled at runtime, report the current state instead of the startup value
+	cfg.Server.MaintenanceMode = middleware.MaintenanceMode()
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data:    cfg,
+	})
+}
+
+// GetStats godoc
+// @Summary 获取运行统计
+// @Description 获取运行时统计信息，如正在进行的订阅获取数量和订阅内容总大小
+// @Tags 系统
+// @Produce json
+// @Success 200 {object} model.SuccessResponse{data=SystemStats} "成功"
+// @Failure 401 {object} model.UnauthorizedResponse{} "未授权"
+// @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
+// @Router /api/system/stats [get]
+// @Security BearerAuth
+func (h *SystemHandler) GetStats(c *gin.Context) {
+	ctx, cancel := requestContext(c, h.config, 5*time.Second)
+	defer cancel()
+
+	totalSize, err := h.subRepo.TotalContentSize(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to get statistics",
+			Data:    nil,
+		})
+		logger.Error("Failed to get content size statistics: %v", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, model.SuccessResponse{
+		Code:    http.StatusOK,
+		Message: "Success",
+		Data: SystemStats{
+			ActiveFetches:    service.ActiveFetches(),
+			TotalContentSize: totalSize,
+		},
 	})
 }
 
 // SetupStaticAssets Sets up frontend static asset handling
+// Without bundled assets (or with server.api_only) only a short landing response is served
 func (h *SystemHandler) SetupStaticAssets(router *gin.Engine) {
 	if h.fsRoot == nil {
-		logger.Error("Static assets not available")
+		h.setupAPIOnly(router)
 		return
 	}
 
@@ -100,3 +719,27 @@ func (h *SystemHandler) SetupStaticAssets(router *gin.Engine) {
 
 	logger.Info("Static assets registered successfully")
 }
+
+// setupAPIOnly Registers the landing page and JSON 404s used when no web UI is served
+func (h *SystemHandler) setupAPIOnly(router *gin.Engine) {
+	router.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, model.SuccessResponse{
+			Code:    http.StatusOK,
+			Message: "BestSub API server is running without a web UI, see /api/swagger/index.html",
+			Data:    nil,
+		})
+	})
+
+	router.NoRoute(func(c *gin.Context) {
+		message := "Not found, this server runs in API-only mode"
+		if strings.HasPrefix(c.Request.URL.Path, "/api/") {
+			message = "API endpoint not found"
+		}
+
+		c.JSON(http.StatusNotFound, model.StandardResponse{
+			Code:    http.StatusNotFound,
+			Message: message,
+			Data:    nil,
+		})
+	})
+}
@@ -0,0 +1,409 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/config"
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/repository"
+	"github.com/bestruirui/bestsub/internal/validator"
+)
+
+func TestValidateCron(t *testing.T) {
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSystemHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	t.Run("valid", func(t *testing.T) {
+		before := time.Now()
+		w := doRequest(t, engine, http.MethodPost, "/api/system/validate-cron", token, ValidateCronRequest{Cron: "*/10 * * * *"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+
+		resp := decodeResponse[ValidateCronResponse](t, w)
+		if !resp.Data.Valid || resp.Data.Error != "" {
+			t.Fatalf("valid = %v, error = %q, want valid", resp.Data.Valid, resp.Data.Error)
+		}
+		if len(resp.Data.NextRuns) != cronPreviewRuns {
+			t.Fatalf("got %d next runs, want %d", len(resp.Data.NextRuns), cronPreviewRuns)
+		}
+
+		prev := before
+		for i, run := range resp.Data.NextRuns {
+			if !run.After(prev) {
+				t.Errorf("run %d (%v) is not after %v", i, run, prev)
+			}
+			if run.Minute()%10 != 0 || run.Second() != 0 {
+				t.Errorf("run %d (%v) does not fall on a 10 minute boundary", i, run)
+			}
+			prev = run
+		}
+		if last := resp.Data.NextRuns[cronPreviewRuns-1]; last.Sub(before) > time.Duration(cronPreviewRuns)*10*time.Minute {
+			t.Errorf("last run %v is too far after %v", last, before)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		w := doRequest(t, engine, http.MethodPost, "/api/system/validate-cron", token, ValidateCronRequest{Cron: "61 * * * *"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+
+		resp := decodeResponse[ValidateCronResponse](t, w)
+		if resp.Data.Valid {
+			t.Fatal("valid = true, want false")
+		}
+		if resp.Data.Error != validator.ErrInvalidCronValue.Error() {
+			t.Errorf("error = %q, want %q", resp.Data.Error, validator.ErrInvalidCronValue.Error())
+		}
+		if len(resp.Data.NextRuns) != 0 {
+			t.Errorf("next runs = %v, want none", resp.Data.NextRuns)
+		}
+	})
+
+	t.Run("missing cron", func(t *testing.T) {
+		w := doRequest(t, engine, http.MethodPost, "/api/system/validate-cron", token, map[string]string{})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", w.Code)
+		}
+	})
+}
+
+func TestRotateJWTSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"jwt":{"secret":"`+testJWTSecret+`"}}`), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load error = %v", err)
+	}
+	cfg.Server.APIOnly = true
+
+	engine := newTestEngine(t, NewSystemHandler(database.DB, cfg))
+	oldToken := testToken(t, cfg, model.AdminUserID)
+
+	if w := doRequest(t, engine, http.MethodGet, "/api/system/maintenance", oldToken, nil); w.Code != http.StatusOK {
+		t.Fatalf("status before rotation = %d, want 200", w.Code)
+	}
+
+	if w := doRequest(t, engine, http.MethodPost, "/api/system/jwt/rotate", oldToken, nil); w.Code != http.StatusOK {
+		t.Fatalf("rotate status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	w := doRequest(t, engine, http.MethodGet, "/api/system/maintenance", oldToken, nil)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status with old token = %d, want 401", w.Code)
+	}
+
+	newToken := testToken(t, cfg, model.AdminUserID)
+	if w := doRequest(t, engine, http.MethodGet, "/api/system/maintenance", newToken, nil); w.Code != http.StatusOK {
+		t.Errorf("status with new token = %d, want 200", w.Code)
+	}
+}
+
+func TestRotateJWTSecretRequiresAdmin(t *testing.T) {
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSystemHandler(database.DB, cfg))
+
+	w := doRequest(t, engine, http.MethodPost, "/api/system/jwt/rotate", testToken(t, cfg, model.AdminUserID+1), nil)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", w.Code)
+	}
+	if cfg.JWT.Secret != testJWTSecret {
+		t.Error("secret was rotated by a non-admin user")
+	}
+}
+
+func TestReadinessCheckPendingMigrations(t *testing.T) {
+	cfg := newTestConfig()
+
+	t.Run("up to date", func(t *testing.T) {
+		engine := newTestEngine(t, NewSystemHandler(database.DB, cfg))
+		w := doRequest(t, engine, http.MethodGet, "/api/health/ready", "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("pending", func(t *testing.T) {
+		// An empty database has not applied any migration
+		db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "empty.db"))
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		defer db.Close()
+
+		engine := newTestEngine(t, NewSystemHandler(db, cfg))
+		w := doRequest(t, engine, http.MethodGet, "/api/health/ready", "", nil)
+		if w.Code != http.StatusServiceUnavailable {
+			t.Fatalf("status = %d, want 503: %s", w.Code, w.Body.String())
+		}
+
+		var status ReadinessStatus
+		if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if status.Checks["database"] != "ok" {
+			t.Errorf("database check = %q, want ok", status.Checks["database"])
+		}
+		want := fmt.Sprintf("%d pending, latest version is %d", database.LatestVersion(), database.LatestVersion())
+		if status.Checks["migrations"] != want {
+			t.Errorf("migrations check = %q, want %q", status.Checks["migrations"], want)
+		}
+	})
+}
+
+func TestHealthCheckFormats(t *testing.T) {
+	engine := newTestEngine(t, NewSystemHandler(database.DB, newTestConfig()))
+
+	t.Run("legacy", func(t *testing.T) {
+		w := doRequest(t, engine, http.MethodGet, "/api/health", "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", w.Code)
+		}
+
+		var body map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(body) != 2 || body["status"] != "ok" || body["time"] == nil {
+			t.Errorf("body = %v, want only status and time", body)
+		}
+	})
+
+	t.Run("standard", func(t *testing.T) {
+		w := doRequest(t, engine, http.MethodGet, "/api/health?format=standard", "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", w.Code)
+		}
+
+		resp := decodeResponse[HealthStatus](t, w)
+		if resp.Code != http.StatusOK || resp.Message != "Success" {
+			t.Errorf("envelope = %d %q, want 200 Success", resp.Code, resp.Message)
+		}
+		if resp.Data.Status != "ok" {
+			t.Errorf("data status = %q, want ok", resp.Data.Status)
+		}
+		if _, err := time.Parse(time.RFC3339, resp.Data.Time); err != nil {
+			t.Errorf("data time %q is not RFC 3339: %v", resp.Data.Time, err)
+		}
+	})
+}
+
+func TestSetupStaticAssetsAPIOnly(t *testing.T) {
+	h := NewSystemHandler(database.DB, newTestConfig())
+	if h.fsRoot != nil {
+		t.Fatal("fsRoot is set although api_only is enabled")
+	}
+
+	engine := newTestEngine(t, h)
+	h.SetupStaticAssets(engine)
+
+	w := doRequest(t, engine, http.MethodGet, "/", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	resp := decodeResponse[any](t, w)
+	if !strings.Contains(resp.Message, "without a web UI") || !strings.Contains(resp.Message, "/api/swagger/index.html") {
+		t.Errorf("message = %q, want a hint to the API docs", resp.Message)
+	}
+
+	tests := []struct {
+		path    string
+		message string
+	}{
+		{"/settings", "Not found, this server runs in API-only mode"},
+		{"/api/unknown", "API endpoint not found"},
+	}
+	for _, tt := range tests {
+		w := doRequest(t, engine, http.MethodGet, tt.path, "", nil)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s status = %d, want 404", tt.path, w.Code)
+		}
+		if got := decodeResponse[any](t, w).Message; got != tt.message {
+			t.Errorf("%s message = %q, want %q", tt.path, got, tt.message)
+		}
+	}
+}
+
+func TestGetStatsTotalContentSize(t *testing.T) {
+	resetTables(t)
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSystemHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	repo := repository.NewSubRepository(database.DB)
+	subs := createTestSubs(t, "http://a.example/sub", "http://b.example/sub")
+	for i, size := range []int64{1200, 34} {
+		if err := repo.UpdateLastFetch(context.Background(), subs[i].ID, size); err != nil {
+			t.Fatalf("UpdateLastFetch error = %v", err)
+		}
+	}
+
+	w := doRequest(t, engine, http.MethodGet, "/api/system/stats", token, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	stats := decodeResponse[SystemStats](t, w).Data
+	if stats.TotalContentSize != 1234 {
+		t.Errorf("total content size = %d, want 1234", stats.TotalContentSize)
+	}
+	if stats.ActiveFetches != 0 {
+		t.Errorf("active fetches = %d, want 0", stats.ActiveFetches)
+	}
+}
+
+func TestReadinessFailsWhileDatabaseDown(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "down.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	db.Close()
+
+	engine := newTestEngine(t, NewSystemHandler(db, newTestConfig()))
+
+	ready := doRequest(t, engine, http.MethodGet, "/api/health/ready", "", nil)
+	if ready.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readiness status = %d, want 503", ready.Code)
+	}
+	var status ReadinessStatus
+	if err := json.Unmarshal(ready.Body.Bytes(), &status); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if status.Status != "unavailable" || status.Checks["database"] == "ok" {
+		t.Errorf("readiness = %+v, want the database check to fail", status)
+	}
+
+	if live := doRequest(t, engine, http.MethodGet, "/api/health/live", "", nil); live.Code != http.StatusOK {
+		t.Errorf("liveness status = %d, want 200", live.Code)
+	}
+	if health := doRequest(t, engine, http.MethodGet, "/api/health", "", nil); health.Code != http.StatusOK {
+		t.Errorf("legacy health status = %d, want 200", health.Code)
+	}
+}
+
+func TestMigrateDatabaseToVersion(t *testing.T) {
+	// An empty database is at version 0
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "empty.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSystemHandler(db, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	w := doRequest(t, engine, http.MethodPost, "/api/system/migrate", token, MigrateRequest{Version: 1})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	resp := decodeResponse[MigrateResponse](t, w).Data
+	if len(resp.Applied) != 1 || resp.Applied[0] != 1 || resp.Version != 1 {
+		t.Errorf("response = %+v, want only version 1 applied", resp)
+	}
+
+	pending, err := database.PendingMigrations(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != database.LatestVersion()-1 || pending[0].Version != 2 {
+		t.Errorf("pending = %d migrations, want versions 2..%d", len(pending), database.LatestVersion())
+	}
+
+	for _, version := range []int{1, database.LatestVersion() + 1} {
+		if w := doRequest(t, engine, http.MethodPost, "/api/system/migrate", token, MigrateRequest{Version: version}); w.Code != http.StatusBadRequest {
+			t.Errorf("migrate to %d status = %d, want 400", version, w.Code)
+		}
+	}
+}
+
+func TestDBStatsInUseDuringTransaction(t *testing.T) {
+	// A separate handle, pool changes must not leak into the shared test database
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "pool.db"))
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewSystemHandler(db, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+
+	w := doRequest(t, engine, http.MethodGet, "/api/system/db-stats", token, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if stats := decodeResponse[DBStats](t, w).Data; stats.InUse < 1 || stats.OpenConnections < 1 {
+		t.Errorf("in_use = %d, open = %d during a held transaction, want at least 1", stats.InUse, stats.OpenConnections)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("failed to roll back: %v", err)
+	}
+
+	w = doRequest(t, engine, http.MethodGet, "/api/system/db-stats", token, nil)
+	if stats := decodeResponse[DBStats](t, w).Data; stats.InUse != 0 {
+		t.Errorf("in_use = %d after rollback, want 0", stats.InUse)
+	}
+
+	w = doRequest(t, engine, http.MethodPut, "/api/system/db-stats", token, map[string]int{"max_open_conns": 3})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if stats := decodeResponse[DBStats](t, w).Data; stats.MaxOpenConnections != 3 {
+		t.Errorf("max_open_connections = %d, want 3", stats.MaxOpenConnections)
+	}
+
+	w = doRequest(t, engine, http.MethodPut, "/api/system/db-stats", token, map[string]int{"max_open_conns": -1})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("negative limit status = %d, want 400", w.Code)
+	}
+}
+
+func TestGetConfigMasksJWTSecret(t *testing.T) {
+	cfg := newTestConfig()
+	cfg.Fetcher.TimeoutSeconds = 42
+	engine := newTestEngine(t, NewSystemHandler(database.DB, cfg))
+
+	w := doRequest(t, engine, http.MethodGet, "/api/system/config", testToken(t, cfg, model.AdminUserID), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), testJWTSecret) {
+		t.Fatalf("response contains the JWT secret: %s", w.Body.String())
+	}
+
+	got := decodeResponse[model.Config](t, w).Data
+	if got.JWT.Secret != config.RedactedValue {
+		t.Errorf("jwt secret = %q, want %q", got.JWT.Secret, config.RedactedValue)
+	}
+	if got.Fetcher.TimeoutSeconds != 42 {
+		t.Errorf("fetcher timeout = %d, want the running value 42", got.Fetcher.TimeoutSeconds)
+	}
+	if cfg.JWT.Secret != testJWTSecret {
+		t.Error("masking changed the running config")
+	}
+
+	w = doRequest(t, engine, http.MethodGet, "/api/system/config", testToken(t, cfg, model.AdminUserID+1), nil)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("non-admin status = %d, want 403", w.Code)
+	}
+}
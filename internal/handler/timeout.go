@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// TimeoutHeader Request header clients use to override the request timeout, in seconds
+const TimeoutHeader = "X-Timeout-Seconds"
+
+// requestTimeout Returns the timeout requested through TimeoutHeader clamped to server.max_request_timeout_seconds
+// ok is false and def is returned when the header is absent, invalid or overrides are disabled
+func requestTimeout(c *gin.Context, cfg *model.Config, def time.Duration) (timeout time.Duration, ok bool) {
+	maxSeconds := cfg.Server.MaxRequestTimeoutSeconds
+	header := c.GetHeader(TimeoutHeader)
+	if maxSeconds <= 0 || header == "" {
+		return def, false
+	}
+
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds <= 0 {
+		return def, false
+	}
+
+	return time.Duration(min(seconds, maxSeconds)) * time.Second, true
+}
+
+// requestContext Derives the handler context from the request with def or the client requested timeout
+func requestContext(c *gin.Context, cfg *model.Config, def time.Duration) (context.Context, context.CancelFunc) {
+	timeout, _ := requestTimeout(c, cfg, def)
+	return context.WithTimeout(c.Request.Context(), timeout)
+}
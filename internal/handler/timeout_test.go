@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	const def = 10 * time.Second
+
+	tests := []struct {
+		name       string
+		maxSeconds int
+		header     string
+		want       time.Duration
+		wantOK     bool
+	}{
+		{"absent", 60, "", def, false},
+		{"within cap", 60, "30", 30 * time.Second, true},
+		{"clamped to cap", 60, "600", 60 * time.Second, true},
+		{"zero", 60, "0", def, false},
+		{"negative", 60, "-5", def, false},
+		{"not a number", 60, "soon", def, false},
+		{"fractional", 60, "1.5", def, false},
+		{"overrides disabled", 0, "30", def, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig()
+			cfg.Server.MaxRequestTimeoutSeconds = tt.maxSeconds
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				c.Request.Header.Set(TimeoutHeader, tt.header)
+			}
+
+			got, ok := requestTimeout(c, cfg, def)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("requestTimeout = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestFetchSubContentTimeoutHeader(t *testing.T) {
+	resetTables(t)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(1200 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		fmt.Fprint(w, "vmess://node")
+	}))
+	t.Cleanup(upstream.Close)
+
+	// The sub allows 1s, the upstream needs 1.2s
+	sub := &model.Sub{URL: upstream.URL, Cron: "0 */1 * * *", FetchTimeoutSeconds: 1}
+	if err := repository.NewSubRepository(database.DB).Create(context.Background(), sub); err != nil {
+		t.Fatalf("failed to create sub: %v", err)
+	}
+	path := fmt.Sprintf("/api/sub/%d/content", sub.ID)
+
+	tests := []struct {
+		name       string
+		maxSeconds int
+		header     string
+		want       int
+	}{
+		{"no header", 2, "", http.StatusGatewayTimeout},
+		{"extended", 2, "2", http.StatusOK},
+		{"clamped", 1, "5", http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig()
+			cfg.Server.MaxRequestTimeoutSeconds = tt.maxSeconds
+			engine := newTestEngine(t, NewSubHandler(database.DB, cfg))
+			token := testToken(t, cfg, model.AdminUserID)
+
+			var headers []string
+			if tt.header != "" {
+				headers = []string{TimeoutHeader, tt.header}
+			}
+			w := doRequest(t, engine, http.MethodGet, path, token, nil, headers...)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
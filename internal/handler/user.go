@@ -1,18 +1,20 @@
 package handler
 
 import (
-	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/bestruirui/bestsub/internal/config"
 	"github.com/bestruirui/bestsub/internal/logger"
 	"github.com/bestruirui/bestsub/internal/middleware"
 	"github.com/bestruirui/bestsub/internal/model"
 	"github.com/bestruirui/bestsub/internal/repository"
 	"github.com/bestruirui/bestsub/internal/router"
 	"github.com/bestruirui/bestsub/internal/service"
+	"github.com/bestruirui/bestsub/internal/validator"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
 )
@@ -28,6 +30,7 @@ const (
 type UserHandler struct {
 	userRepo repository.UserRepository
 	userSvc  *service.UserService
+	auditSvc *service.AuditService
 	config   *model.Config
 }
 
@@ -36,7 +39,8 @@ func NewUserHandler(db *sql.DB, config *model.Config) *UserHandler {
 	userRepo := repository.NewUserRepository(db)
 	return &UserHandler{
 		userRepo: userRepo,
-		userSvc:  service.NewUserService(userRepo),
+		userSvc:  service.NewUserService(userRepo, config.Password.Algorithm, config.Password.BcryptCost),
+		auditSvc: service.NewAuditService(repository.NewAuditRepository(db)),
 		config:   config,
 	}
 }
@@ -58,7 +62,7 @@ func (h *UserHandler) Groups() []*router.GroupRouter {
 func (h *UserHandler) UserGroup() *router.GroupRouter {
 	// Use chain API to create route group
 	return router.NewGroupRouter("/api/user").
-		Use(middleware.JWTAuth(h.config)).
+		Use(middleware.JWTAuth(h.config), middleware.RouteTimeout(h.config, "/api/user")).
 		AddRoute(
 			router.NewRoute("/logout", router.POST).
 				Handle(h.Logout).
@@ -103,7 +107,7 @@ type LoginResponse struct {
 // @Failure 500 {object} model.ServerErrorResponse{} "服务器内部错误"
 // @Router /api/user/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), RequestTimeout)
+	ctx, cancel := requestContext(c, h.config, RequestTimeout)
 	defer cancel()
 
 	var req LoginRequest
@@ -124,6 +128,7 @@ func (h *UserHandler) Login(c *gin.Context) {
 		if errors.Is(err, service.ErrInvalidCredentials) {
 			status = http.StatusUnauthorized
 			message = "Invalid username or password"
+			h.auditSvc.Record(0, model.AuditUserLoginFailed, "username:"+req.Username)
 		}
 
 		c.JSON(status, model.ServerErrorResponse{
@@ -142,12 +147,24 @@ func (h *UserHandler) Login(c *gin.Context) {
 	expTime := time.Now().Add(time.Hour * time.Duration(expiresIn))
 	expUnix := expTime.Unix()
 
+	jti, err := config.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
+			Code:    http.StatusInternalServerError,
+			Message: "Failed to generate token",
+			Data:    nil,
+		})
+		logger.Error("Failed to generate JWT ID: %v", err)
+		return
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": user.ID,
 		"exp":     expUnix,
+		"jti":     jti,
 	})
 
-	tokenString, err := token.SignedString([]byte(h.config.JWT.Secret))
+	tokenString, err := token.SignedString(config.JWTSecret(h.config))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, model.ServerErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -158,6 +175,8 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
+	h.auditSvc.Record(user.ID, model.AuditUserLogin, fmt.Sprintf("user:%d", user.ID))
+
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Code:    http.StatusOK,
 		Message: "Login successful",
@@ -192,7 +211,16 @@ func (h *UserHandler) Logout(c *gin.Context) {
 		return
 	}
 
+	// Tokens issued before token IDs were introduced cannot be revoked and stay valid until they expire
+	if jti := c.GetString("token_id"); jti != "" {
+		expiresAt := time.Unix(c.GetInt64("token_exp"), 0)
+		if err := service.RevokeToken(c.Request.Context(), jti, expiresAt); err != nil {
+			logger.Error("Failed to persist revoked token: %v", err)
+		}
+	}
+
 	logger.Info("User logged out: UserID=%d", userID.(int64))
+	h.auditSvc.Record(userID.(int64), model.AuditUserLogout, fmt.Sprintf("user:%d", userID.(int64)))
 
 	c.JSON(http.StatusOK, model.SuccessResponse{
 		Code:    http.StatusOK,
@@ -214,7 +242,7 @@ func (h *UserHandler) Logout(c *gin.Context) {
 // @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
 // @Router /api/user/info [get]
 func (h *UserHandler) GetUserInfo(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), RequestTimeout)
+	ctx, cancel := requestContext(c, h.config, RequestTimeout)
 	defer cancel()
 
 	userID, exists := c.Get("user_id")
@@ -275,7 +303,7 @@ type UpdateUserInfoRequest struct {
 // @Failure 500 {object} model.ServerErrorResponse{} "服务器错误"
 // @Router /api/user/info [put]
 func (h *UserHandler) UpdateUserInfo(c *gin.Context) {
-	ctx, cancel := context.WithTimeout(c.Request.Context(), RequestTimeout)
+	ctx, cancel := requestContext(c, h.config, RequestTimeout)
 	defer cancel()
 
 	userID, exists := c.Get("user_id")
@@ -298,6 +326,18 @@ func (h *UserHandler) UpdateUserInfo(c *gin.Context) {
 		return
 	}
 
+	// Validate before changing anything so a bad username does not leave a half-applied update
+	if req.Username != "" {
+		if err := validator.ValidateUsername(req.Username); err != nil {
+			c.JSON(http.StatusBadRequest, model.BadRequestResponse{
+				Code:    http.StatusBadRequest,
+				Message: err.Error(),
+				Data:    nil,
+			})
+			return
+		}
+	}
+
 	user, err := h.userRepo.GetByID(ctx, userID.(int64))
 	if err != nil {
 		status := http.StatusInternalServerError
@@ -335,6 +375,8 @@ func (h *UserHandler) UpdateUserInfo(c *gin.Context) {
 			logger.Error("Failed to change password: %v", err)
 			return
 		}
+
+		h.auditSvc.Record(user.ID, model.AuditUserPassword, fmt.Sprintf("user:%d", user.ID))
 	}
 
 	if req.Username != "" && req.Username != user.Username {
@@ -356,6 +398,8 @@ func (h *UserHandler) UpdateUserInfo(c *gin.Context) {
 			logger.Error("Failed to update username: %v", err)
 			return
 		}
+
+		h.auditSvc.Record(user.ID, model.AuditUserUpdate, fmt.Sprintf("user:%d", user.ID))
 	}
 
 	c.JSON(http.StatusOK, model.SuccessResponse{
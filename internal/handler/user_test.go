@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/repository"
+	"github.com/bestruirui/bestsub/internal/service"
+	"github.com/bestruirui/bestsub/internal/validator"
+)
+
+// login Logs in through the API and returns the issued token
+func login(t *testing.T, engine http.Handler, username, password string) string {
+	t.Helper()
+
+	w := doRequest(t, engine, http.MethodPost, "/api/user/login", "", LoginRequest{Username: username, Password: password})
+	if w.Code != http.StatusOK {
+		t.Fatalf("login status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	return decodeResponse[LoginResponse](t, w).Data.Token
+}
+
+// reloadTokenBlacklist Loads the persisted blacklist as on startup
+func reloadTokenBlacklist(t *testing.T) {
+	t.Helper()
+
+	if err := service.InitTokenBlacklist(context.Background(), repository.NewTokenBlacklistRepository(database.DB)); err != nil {
+		t.Fatalf("InitTokenBlacklist error = %v", err)
+	}
+}
+
+func TestLogoutSurvivesRestart(t *testing.T) {
+	reloadTokenBlacklist(t)
+	engine := newTestEngine(t, NewUserHandler(database.DB, newTestConfig()))
+
+	token := login(t, engine, "admin", "admin")
+	if w := doRequest(t, engine, http.MethodGet, "/api/user/info", token, nil); w.Code != http.StatusOK {
+		t.Fatalf("info status before logout = %d, want 200", w.Code)
+	}
+
+	if w := doRequest(t, engine, http.MethodPost, "/api/user/logout", token, nil); w.Code != http.StatusOK {
+		t.Fatalf("logout status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	// A restart starts with an empty in-memory blacklist and loads it from the database
+	reloadTokenBlacklist(t)
+
+	if w := doRequest(t, engine, http.MethodGet, "/api/user/info", token, nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("info status with logged out token after restart = %d, want 401", w.Code)
+	}
+
+	// Other sessions stay valid
+	other := login(t, engine, "admin", "admin")
+	if w := doRequest(t, engine, http.MethodGet, "/api/user/info", other, nil); w.Code != http.StatusOK {
+		t.Errorf("info status with a new token = %d, want 200", w.Code)
+	}
+}
+
+func TestUpdateUsernameValidation(t *testing.T) {
+	cfg := newTestConfig()
+	engine := newTestEngine(t, NewUserHandler(database.DB, cfg))
+	token := testToken(t, cfg, model.AdminUserID)
+
+	for _, username := range []string{strings.Repeat("a", validator.MaxUsernameLength+1), "bad name\x07"} {
+		w := doRequest(t, engine, http.MethodPut, "/api/user/info", token, UpdateUserInfoRequest{Username: username})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("update to %q status = %d, want 400", username, w.Code)
+		}
+	}
+
+	user, err := repository.NewUserRepository(database.DB).GetByID(context.Background(), model.AdminUserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Username != "admin" {
+		t.Errorf("username = %q, want it unchanged", user.Username)
+	}
+}
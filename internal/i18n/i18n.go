@@ -0,0 +1,185 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported languages
+const (
+	LangEN = "en"
+	LangZH = "zh"
+)
+
+// DefaultLang Language used when the client does not ask for a supported one
+const DefaultLang = LangEN
+
+// catalog Translations keyed by the English API message, which doubles as the message code
+var catalog = map[string]map[string]string{
+	LangZH: {
+		// Generic
+		"Success":                    "成功",
+		"Unauthorized":               "未授权",
+		"Internal server error":      "服务器内部错误",
+		"Invalid request data":       "无效的请求数据",
+		"Invalid request parameters": "无效的请求参数",
+		"Invalid limit value":        "无效的分页数量",
+		"Invalid offset value":       "无效的偏移量",
+		"API endpoint not found":     "接口不存在",
+
+		// Authentication
+		"missing authentication header":           "缺少认证请求头",
+		"invalid authentication format":           "认证格式无效",
+		"invalid or expired token":                "令牌无效或已过期",
+		"invalid token claims":                    "令牌声明无效",
+		"invalid user ID in token":                "令牌中的用户ID无效",
+		"token expired":                           "令牌已过期",
+		"token has been revoked":                  "令牌已被注销",
+		"administrator privileges required":       "需要管理员权限",
+		"Access denied from this IP address":      "该IP地址无权访问",
+		"Login successful":                        "登录成功",
+		"Logout successful":                       "登出成功",
+		"Invalid username or password":            "用户名或密码错误",
+		"Failed to generate token":                "生成令牌失败",
+		"JWT secret rotated, please log in again": "JWT密钥已轮换，请重新登录",
+
+		// User
+		"User not found":                                         "用户不存在",
+		"Username already exists":                                "用户名已存在",
+		"Invalid old password":                                   "原密码错误",
+		"Failed to update password":                              "更新密码失败",
+		"Failed to update username":                              "更新用户名失败",
+		"User information updated successfully":                  "用户信息已更新",
+		"username may only contain letters, digits, '_' and '-'": "用户名只能包含字母、数字、下划线和连字符",
+
+		// Subscription
+		"Subscription not found":                                                     "订阅不存在",
+		"Subscription URL already exists":                                            "订阅URL已存在",
+		"Subscription created successfully":                                          "订阅创建成功",
+		"Subscription updated successfully":                                          "订阅更新成功",
+		"Subscription deleted successfully":                                          "订阅删除成功",
+		"Subscriptions reordered successfully":                                       "订阅排序已更新",
+		"Subscription content not found":                                             "订阅内容不存在",
+		"Invalid subscription ID":                                                    "无效的订阅ID",
+		"Invalid subscription URL":                                                   "无效的订阅URL",
+		"Invalid subscription URL template":                                          "无效的订阅URL模板",
+		"Invalid cron expression":                                                    "无效的cron表达式",
+		"Invalid success pattern":                                                    "无效的成功匹配规则",
+		"Invalid min_alive value":                                                    "无效的最少存活节点数",
+		"Invalid after_id value":                                                     "无效的游标",
+		"Duplicate subscription ID in order":                                         "排序中存在重复的订阅ID",
+		"Failed to create subscription":                                              "创建订阅失败",
+		"Failed to update subscription":                                              "更新订阅失败",
+		"Failed to delete subscription":                                              "删除订阅失败",
+		"Failed to retrieve subscription":                                            "获取订阅失败",
+		"Failed to retrieve subscriptions":                                           "获取订阅列表失败",
+		"Failed to reorder subscriptions":                                            "调整订阅顺序失败",
+		"Failed to fetch subscription content":                                       "获取订阅内容失败",
+		"Failed to fetch subscription data":                                          "获取订阅数据失败",
+		"Subscription returned empty content, previous content kept":                 "订阅返回空内容，已保留之前的内容",
+		"Subscription content does not match success pattern, previous content kept": "订阅内容不符合成功匹配规则，已保留之前的内容",
+		"Snapshot saved":                                                             "快照已保存",
+		"Snapshot loaded":                                                            "快照已加载",
+		"Snapshot not found":                                                         "快照不存在",
+		"Invalid snapshot name":                                                      "无效的快照名称",
+		"Failed to save content snapshot":                                            "保存内容快照失败",
+		"Failed to load content snapshot":                                            "加载内容快照失败",
+		"Subscription fetch timed out":                                               "获取订阅超时",
+		"Subscription host could not be resolved":                                    "无法解析订阅主机",
+		"Subscription server returned an error status":                               "订阅服务器返回错误状态",
+		"Failed to read subscription response":                                       "读取订阅响应失败",
+		"Failed to connect to subscription server":                                   "连接订阅服务器失败",
+		"Subscriptions imported":                                                     "订阅已导入",
+		"Failed to fetch import list":                                                "获取导入列表失败",
+		"Import list contains no URLs":                                               "导入列表中没有URL",
+		"Subscription statistics updated":                                            "订阅统计已更新",
+		"Failed to update subscription statistics":                                   "更新订阅统计失败",
+		"Alive nodes cannot exceed total nodes":                                      "存活节点数不能大于节点总数",
+		"Subscription pinned":                                                        "订阅内容已固定",
+		"Subscription unpinned":                                                      "订阅内容已取消固定",
+		"Failed to update pinned state":                                              "更新固定状态失败",
+
+		// System
+		"Service is in maintenance mode, write operations are disabled": "服务维护中，写操作已禁用",
+		"Maintenance mode updated":                                      "维护模式已更新",
+		"Failed to rotate JWT secret":                                   "轮换JWT密钥失败",
+		"Failed to retrieve audit logs":                                 "获取审计日志失败",
+		"Failed to get statistics":                                      "获取统计信息失败",
+		"Migrations applied":                                            "迁移已完成",
+		"Failed to apply migrations":                                    "数据库迁移失败",
+		"Database pool updated":                                         "数据库连接池已更新",
+		"Not found, this server runs in API-only mode":                  "未找到，该服务运行在仅API模式",
+		"Request timed out":                                             "请求超时",
+		"Too many concurrent requests":                                  "并发请求过多",
+	},
+}
+
+// NegotiateLanguage Picks the supported language preferred by an Accept-Language header
+// Region subtags are ignored (zh-CN matches zh), unknown or empty headers yield DefaultLang
+func NegotiateLanguage(acceptLanguage string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+
+		base, _, _ := strings.Cut(tag, "-")
+		candidates = append(candidates, candidate{lang: base, q: q})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	for _, c := range candidates {
+		if c.q <= 0 {
+			continue
+		}
+		if c.lang == LangEN {
+			return LangEN
+		}
+		if _, ok := catalog[c.lang]; ok {
+			return c.lang
+		}
+	}
+
+	return DefaultLang
+}
+
+// Translate Returns message in lang, messages without a translation are returned unchanged
+// For "Message: detail" only the message part is translated
+func Translate(lang, message string) string {
+	messages, ok := catalog[lang]
+	if !ok {
+		return message
+	}
+
+	if translated, ok := messages[message]; ok {
+		return translated
+	}
+
+	if prefix, detail, found := strings.Cut(message, ": "); found {
+		if translated, ok := messages[prefix]; ok {
+			return translated + ": " + detail
+		}
+	}
+
+	return message
+}
@@ -3,8 +3,11 @@ package logger
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"runtime"
+	"sync"
 	"time"
 )
 
@@ -12,6 +15,12 @@ type LogLevel int
 
 var LogLevelSet LogLevel
 
+var (
+	// output Destination of all log lines, stdout unless replaced with SetOutput
+	output   io.Writer = os.Stdout
+	outputMu sync.Mutex
+)
+
 const (
 	LogLevelDebug LogLevel = iota
 	LogLevelInfo
@@ -86,7 +95,19 @@ func log(level LogLevel, format string, v ...any) {
 	buf.WriteString(fmt.Sprintf(format, v...))
 	buf.WriteByte('\n')
 
-	fmt.Print(buf.String())
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	output.Write(buf.Bytes())
+}
+
+// SetOutput Redirects log lines to w and returns the previous destination
+func SetOutput(w io.Writer) io.Writer {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+
+	prev := output
+	output = w
+	return prev
 }
 func SetLogLevel(level string) {
 	switch level {
@@ -104,6 +125,12 @@ func SetLogLevel(level string) {
 		LogLevelSet = LogLevelPanic
 	}
 }
+
+// DebugEnabled Reports whether debug messages are currently logged
+func DebugEnabled() bool {
+	return LogLevelSet <= LogLevelDebug
+}
+
 func Info(format string, v ...any) {
 	log(LogLevelInfo, format, v...)
 }
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/bestruirui/bestsub/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// maxLoggedBodyBytes Bodies are truncated to this many bytes in the log
+const maxLoggedBodyBytes = 4096
+
+// secretFieldPattern JSON string fields whose value must never reach the log
+var secretFieldPattern = regexp.MustCompile(`(?i)("[^"]*(?:password|token|secret)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// secretParamPattern Query parameters of URLs and fields of form bodies whose value must never reach the log
+// JSON encoding escapes & in URLs as \u0026, so that separator is matched too
+var secretParamPattern = regexp.MustCompile(`(?i)((?:^|[?&]|\\u0026)[^=&"\s\\]*(?:password|passwd|token|secret|key|auth)[^=&"\s\\]*=)[^&"\s#\\]*`)
+
+// urlVarsPattern url_vars objects, their values are credentials spliced into subscription URLs
+var urlVarsPattern = regexp.MustCompile(`("url_vars"\s*:\s*)\{[^{}]*\}`)
+
+// jsonStringValuePattern String values of a flat JSON object
+var jsonStringValuePattern = regexp.MustCompile(`(:\s*)"(?:[^"\\]|\\.)*"`)
+
+// BodyLogger Request and response body logging middleware
+// Only active while the log level is debug. The request body is restored after reading so handlers
+// still see all of it, bodies are truncated to maxLoggedBodyBytes and secrets are redacted: password/token/secret
+// fields, url_vars values and token-like query or form parameters. Bodies of the routes in skipPaths, such as the
+// subscription content carrying proxy credentials, are not logged at all.
+func BodyLogger(skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if !logger.DebugEnabled() || skip[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		var reqBody []byte
+		if c.Request.Body != nil {
+			// Only the logged prefix is buffered, the rest is still streamed from the original body
+			reqBody, _ = io.ReadAll(io.LimitReader(c.Request.Body, maxLoggedBodyBytes+1))
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(reqBody), c.Request.Body), c.Request.Body}
+		}
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		logger.Debug("[HTTP] %s %s request: %s response: %s",
+			c.Request.Method,
+			c.Request.URL.Path,
+			formatLoggedBody(c.ContentType(), reqBody),
+			formatLoggedBody(writer.Header().Get("Content-Type"), writer.buf.Bytes()),
+		)
+	}
+}
+
+// bodyCaptureWriter Copies the first maxLoggedBodyBytes+1 bytes of the response body
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *bodyCaptureWriter) capture(data []byte) {
+	if room := maxLoggedBodyBytes + 1 - w.buf.Len(); room > 0 {
+		w.buf.Write(data[:min(len(data), room)])
+	}
+}
+
+func (w *bodyCaptureWriter) Write(data []byte) (int, error) {
+	w.capture(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// formatLoggedBody Renders a body for the log, binary content is only described by its size
+func formatLoggedBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return "-"
+	}
+
+	if !isTextContent(contentType) {
+		return fmt.Sprintf("<%s body>", contentType)
+	}
+
+	truncated := len(body) > maxLoggedBodyBytes
+	if truncated {
+		body = body[:maxLoggedBodyBytes]
+	}
+
+	text := redactSecrets(string(body))
+	if truncated {
+		text += "...(truncated)"
+	}
+	return text
+}
+
+// redactSecrets Masks secret JSON fields, url_vars values and secret query or form parameters
+func redactSecrets(text string) string {
+	text = secretFieldPattern.ReplaceAllString(text, `$1"***"`)
+	text = urlVarsPattern.ReplaceAllStringFunc(text, func(vars string) string {
+		return jsonStringValuePattern.ReplaceAllString(vars, `$1"***"`)
+	})
+	return secretParamPattern.ReplaceAllString(text, "${1}***")
+}
+
+// isTextContent Reports whether a content type is safe to print
+func isTextContent(contentType string) bool {
+	return contentType == "" ||
+		strings.HasPrefix(contentType, "text/") ||
+		strings.Contains(contentType, "json") ||
+		strings.Contains(contentType, "x-www-form-urlencoded")
+}
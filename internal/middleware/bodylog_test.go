@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bestruirui/bestsub/internal/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// newBodyLogEngine Echoes request bodies on POST /echo and serves sub content on GET /api/sub/:id/content
+func newBodyLogEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(BodyLogger("/api/sub/:id/content"))
+	engine.POST("/echo", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Data(http.StatusOK, c.ContentType(), body)
+	})
+	engine.GET("/api/sub/:id/content", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"content": "vmess://proxy-credentials"})
+	})
+	return engine
+}
+
+// captureBodyLogs Collects log output at level until the test ends
+func captureBodyLogs(t *testing.T, level logger.LogLevel) *bytes.Buffer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	prevOutput := logger.SetOutput(buf)
+	prevLevel := logger.LogLevelSet
+	logger.LogLevelSet = level
+	t.Cleanup(func() {
+		logger.SetOutput(prevOutput)
+		logger.LogLevelSet = prevLevel
+	})
+	return buf
+}
+
+// postEcho Sends body to POST /echo and checks the handler saw all of it
+func postEcho(t *testing.T, engine http.Handler, contentType, body string) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Body.String() != body {
+		t.Fatalf("handler read %d bytes, want the full %d byte body", w.Body.Len(), len(body))
+	}
+}
+
+func TestBodyLoggerLevels(t *testing.T) {
+	engine := newBodyLogEngine()
+	body := `{"url":"http://a.example/sub","remark":"work VPN"}`
+
+	logs := captureBodyLogs(t, logger.LogLevelInfo)
+	postEcho(t, engine, "application/json", body)
+	if strings.Contains(logs.String(), "work VPN") {
+		t.Errorf("body logged at info level: %s", logs.String())
+	}
+
+	logs = captureBodyLogs(t, logger.LogLevelDebug)
+	postEcho(t, engine, "application/json", body)
+	if got := strings.Count(logs.String(), "work VPN"); got != 2 {
+		t.Errorf("body logged %d times at debug level, want request and response: %s", got, logs.String())
+	}
+}
+
+func TestBodyLoggerTruncates(t *testing.T) {
+	engine := newBodyLogEngine()
+	logs := captureBodyLogs(t, logger.LogLevelDebug)
+
+	postEcho(t, engine, "text/plain", strings.Repeat("a", 3*maxLoggedBodyBytes))
+	if !strings.Contains(logs.String(), "...(truncated)") || strings.Contains(logs.String(), strings.Repeat("a", maxLoggedBodyBytes+1)) {
+		t.Errorf("large body was not truncated in the log")
+	}
+}
+
+func TestBodyLoggerRedactsSecrets(t *testing.T) {
+	engine := newBodyLogEngine()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		secret      string
+	}{
+		{"password field", "application/json", `{"username":"admin","password":"hunter2"}`, "hunter2"},
+		{"token query", "application/json", `{"url":"https://a.example/sub?token=s3cr3t&flag=clash"}`, "s3cr3t"},
+		{"escaped token query", "application/json", `{"url":"https://a.example/sub?flag=clash\u0026token=s3cr3t"}`, "s3cr3t"},
+		{"url vars", "application/json", `{"url":"https://a.example/{{.Path}}","url_vars":{"Path":"s3cr3t"}}`, "s3cr3t"},
+		{"form password", "application/x-www-form-urlencoded", "password=hunter2&username=admin", "hunter2"},
+		{"form key", "application/x-www-form-urlencoded", "username=admin&api_key=s3cr3t", "s3cr3t"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureBodyLogs(t, logger.LogLevelDebug)
+			postEcho(t, engine, tt.contentType, tt.body)
+
+			if strings.Contains(logs.String(), tt.secret) {
+				t.Errorf("secret %q logged: %s", tt.secret, logs.String())
+			}
+			if !strings.Contains(logs.String(), "***") {
+				t.Errorf("no redaction marker in log: %s", logs.String())
+			}
+		})
+	}
+}
+
+func TestBodyLoggerSkipsContentRoute(t *testing.T) {
+	engine := newBodyLogEngine()
+	logs := captureBodyLogs(t, logger.LogLevelDebug)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/sub/1/content", nil))
+	if !strings.Contains(w.Body.String(), "proxy-credentials") {
+		t.Fatalf("response = %q, want the content", w.Body.String())
+	}
+	if strings.Contains(logs.String(), "proxy-credentials") {
+		t.Errorf("content logged: %s", logs.String())
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// ConnLimit Per client IP concurrent request limit middleware
+// Counts requests in flight per IP and rejects new ones with 429 while an IP is at max,
+// which bounds what a single client can hold open on slow or streaming endpoints. max <= 0 disables the limit.
+func ConnLimit(max int) gin.HandlerFunc {
+	if max <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	var mu sync.Mutex
+	active := make(map[string]int)
+
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+
+		mu.Lock()
+		if active[ip] >= max {
+			mu.Unlock()
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, model.StandardResponse{
+				Code:    http.StatusTooManyRequests,
+				Message: "Too many concurrent requests",
+				Data:    nil,
+			})
+			return
+		}
+		active[ip]++
+		mu.Unlock()
+
+		defer func() {
+			mu.Lock()
+			if active[ip] <= 1 {
+				delete(active, ip)
+			} else {
+				active[ip]--
+			}
+			mu.Unlock()
+		}()
+
+		c.Next()
+	}
+}
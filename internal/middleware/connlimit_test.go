@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serveFrom Sends GET path from remoteIP and returns the status
+func serveFrom(engine http.Handler, path, remoteIP string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = net.JoinHostPort(remoteIP, "12345")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestConnLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const limit = 2
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	engine := gin.New()
+	engine.Use(ConnLimit(limit))
+	engine.GET("/hold", func(c *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	engine.GET("/fast", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	var wg sync.WaitGroup
+	held := make([]int, limit)
+	for i := range limit {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			held[i] = serveFrom(engine, "/hold", "10.0.0.1")
+		}()
+		<-entered
+	}
+
+	if got := serveFrom(engine, "/fast", "10.0.0.1"); got != http.StatusTooManyRequests {
+		t.Errorf("request over the limit status = %d, want 429", got)
+	}
+	if got := serveFrom(engine, "/fast", "10.0.0.2"); got != http.StatusOK {
+		t.Errorf("request from another IP status = %d, want 200", got)
+	}
+
+	close(release)
+	wg.Wait()
+	for i, status := range held {
+		if status != http.StatusOK {
+			t.Errorf("held request %d status = %d, want 200", i, status)
+		}
+	}
+
+	if got := serveFrom(engine, "/fast", "10.0.0.1"); got != http.StatusOK {
+		t.Errorf("request after release status = %d, want 200", got)
+	}
+}
+
+func TestConnLimitDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(ConnLimit(0))
+	engine.GET("/fast", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	if got := serveFrom(engine, "/fast", "10.0.0.1"); got != http.StatusOK {
+		t.Errorf("status = %d, want 200", got)
+	}
+}
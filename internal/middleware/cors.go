@@ -18,7 +18,7 @@ func Cors() gin.HandlerFunc {
 		if gin.Mode() == gin.DebugMode {
 			c.Header("Access-Control-Allow-Origin", "*")
 			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+			c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Timeout-Seconds")
 			c.Header("Access-Control-Allow-Credentials", "true")
 
 			if c.Request.Method == "OPTIONS" {
@@ -30,7 +30,7 @@ func Cors() gin.HandlerFunc {
 			if origin == "http://"+host || origin == "https://"+host {
 				c.Header("Access-Control-Allow-Origin", origin)
 				c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-				c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+				c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Timeout-Seconds")
 				c.Header("Access-Control-Allow-Credentials", "true")
 
 				if c.Request.Method == "OPTIONS" {
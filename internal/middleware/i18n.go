@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/bestruirui/bestsub/internal/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// I18n Response message localization middleware
+// Negotiates the language from Accept-Language and translates the message field of JSON
+// responses in the standard {code, message, data} envelope. English responses pass through untouched.
+func I18n() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		lang := i18n.NegotiateLanguage(c.GetHeader("Accept-Language"))
+		if lang == i18n.LangEN {
+			c.Next()
+			return
+		}
+
+		writer := &translatingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		writer.flush(lang)
+	}
+}
+
+// translatingWriter Buffers JSON bodies so their message can be translated before sending
+type translatingWriter struct {
+	gin.ResponseWriter
+	buf     bytes.Buffer
+	decided bool
+	buffer  bool
+}
+
+// buffering Decides on the first write whether the body is JSON and should be buffered
+func (w *translatingWriter) buffering() bool {
+	if !w.decided {
+		w.decided = true
+		w.buffer = strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+	}
+	return w.buffer
+}
+
+func (w *translatingWriter) Write(data []byte) (int, error) {
+	if w.buffering() {
+		return w.buf.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *translatingWriter) WriteString(s string) (int, error) {
+	if w.buffering() {
+		return w.buf.WriteString(s)
+	}
+	return w.ResponseWriter.WriteString(s)
+}
+
+// flush Writes the buffered body, translating the message when it is a standard envelope
+// Only the message is replaced, every other top-level field is written back unchanged
+func (w *translatingWriter) flush(lang string) {
+	if !w.buffer {
+		return
+	}
+
+	body := w.buf.Bytes()
+	var resp map[string]json.RawMessage
+	if err := json.Unmarshal(body, &resp); err == nil && resp["code"] != nil {
+		var message *string
+		if err := json.Unmarshal(resp["message"], &message); err == nil && message != nil {
+			if translated, err := json.Marshal(i18n.Translate(lang, *message)); err == nil {
+				resp["message"] = translated
+				if out, err := json.Marshal(resp); err == nil {
+					body = out
+				}
+			}
+		}
+	}
+
+	w.ResponseWriter.Write(body)
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newI18nEngine Serves GET /missing with a 404 envelope and GET /text with plain text behind I18n
+func newI18nEngine() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(I18n())
+	engine.GET("/missing", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code":       http.StatusNotFound,
+			"message":    "Subscription not found",
+			"data":       nil,
+			"request_id": "abc",
+		})
+	})
+	engine.GET("/text", func(c *gin.Context) {
+		c.String(http.StatusOK, "Subscription not found")
+	})
+	return engine
+}
+
+func TestI18nTranslatesMessage(t *testing.T) {
+	engine := newI18nEngine()
+
+	tests := []struct {
+		acceptLanguage string
+		want           string
+	}{
+		{"zh-CN,zh;q=0.9,en;q=0.8", "订阅不存在"},
+		{"en-US,zh;q=0.5", "Subscription not found"},
+		{"fr-FR", "Subscription not found"},
+		{"", "Subscription not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want 404", w.Code)
+			}
+
+			var resp map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response %q: %v", w.Body.String(), err)
+			}
+			if resp["message"] != tt.want {
+				t.Errorf("message = %v, want %q", resp["message"], tt.want)
+			}
+			if resp["code"] != float64(http.StatusNotFound) || resp["request_id"] != "abc" {
+				t.Errorf("response = %v, want code and request_id kept", resp)
+			}
+			if data, ok := resp["data"]; !ok || data != nil {
+				t.Errorf("data = %v (present %v), want null", data, ok)
+			}
+		})
+	}
+}
+
+func TestI18nLeavesNonJSONBodies(t *testing.T) {
+	engine := newI18nEngine()
+
+	req := httptest.NewRequest(http.MethodGet, "/text", nil)
+	req.Header.Set("Accept-Language", "zh")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	if w.Body.String() != "Subscription not found" {
+		t.Errorf("body = %q, want the plain text unchanged", w.Body.String())
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/bestruirui/bestsub/internal/logger"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// AdminIPAllowList Restricts admin routes to the IPs/CIDRs in config.Server.AdminAllowedCIDRs
+// An empty list allows every address. The client IP comes from gin's trusted-proxy aware ClientIP
+// config.Load rejects invalid entries, should a non-empty list still contain no valid entry every address is blocked
+func AdminIPAllowList(cfg *model.Config) gin.HandlerFunc {
+	networks, configured := parseCIDRs(cfg.Server.AdminAllowedCIDRs)
+	if configured && len(networks) == 0 {
+		logger.Error("No valid admin allowed CIDR configured, admin endpoints are blocked for all addresses")
+	}
+
+	return func(c *gin.Context) {
+		if !configured {
+			c.Next()
+			return
+		}
+
+		ip := net.ParseIP(c.ClientIP())
+		if ip != nil {
+			for _, network := range networks {
+				if network.Contains(ip) {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		logger.Warn("Admin request from disallowed IP: %s", c.ClientIP())
+		c.AbortWithStatusJSON(http.StatusForbidden, model.StandardResponse{
+			Code:    http.StatusForbidden,
+			Message: "Access denied from this IP address",
+			Data:    nil,
+		})
+	}
+}
+
+// parseCIDRs Parses CIDR notations and bare IPs, skipping invalid entries with a warning
+// configured reports whether the list has any non-blank entry, valid or not
+func parseCIDRs(entries []string) (networks []*net.IPNet, configured bool) {
+	networks = make([]*net.IPNet, 0, len(entries))
+
+	for _, entry := range entries {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		configured = true
+
+		network, err := validator.ParseIPOrCIDR(entry)
+		if err != nil {
+			logger.Warn("Ignoring invalid admin allowed CIDR: %s", entry)
+			continue
+		}
+		networks = append(networks, network)
+	}
+
+	return networks, configured
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// newAllowListEngine Serves GET /admin behind AdminIPAllowList with cidrs
+func newAllowListEngine(cidrs ...string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &model.Config{}
+	cfg.Server.AdminAllowedCIDRs = cidrs
+
+	engine := gin.New()
+	engine.GET("/admin", AdminIPAllowList(cfg), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return engine
+}
+
+// requestFrom Sends GET /admin from remoteIP and returns the status
+func requestFrom(engine http.Handler, remoteIP string) int {
+	req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	req.RemoteAddr = net.JoinHostPort(remoteIP, "12345")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestAdminIPAllowList(t *testing.T) {
+	engine := newAllowListEngine("10.0.0.0/8", "192.168.1.5", "2001:db8::/32")
+
+	tests := []struct {
+		ip   string
+		want int
+	}{
+		{"10.1.2.3", http.StatusOK},
+		{"192.168.1.5", http.StatusOK},
+		{"2001:db8::1", http.StatusOK},
+		{"192.168.1.6", http.StatusForbidden},
+		{"11.0.0.1", http.StatusForbidden},
+		{"2001:db9::1", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		if got := requestFrom(engine, tt.ip); got != tt.want {
+			t.Errorf("request from %s status = %d, want %d", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestAdminIPAllowListEmpty(t *testing.T) {
+	for _, cidrs := range [][]string{nil, {" "}} {
+		if got := requestFrom(newAllowListEngine(cidrs...), "203.0.113.7"); got != http.StatusOK {
+			t.Errorf("list %q status = %d, want 200", cidrs, got)
+		}
+	}
+}
+
+func TestAdminIPAllowListOnlyInvalidBlocksAll(t *testing.T) {
+	engine := newAllowListEngine("10.0.0.0/33", "not-an-ip")
+
+	for _, ip := range []string{"10.0.0.1", "127.0.0.1", "203.0.113.7"} {
+		if got := requestFrom(engine, ip); got != http.StatusForbidden {
+			t.Errorf("request from %s status = %d, want 403", ip, got)
+		}
+	}
+}
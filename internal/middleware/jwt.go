@@ -6,8 +6,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bestruirui/bestsub/internal/config"
 	"github.com/bestruirui/bestsub/internal/logger"
 	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/service"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
 )
@@ -18,11 +20,13 @@ var (
 	ErrInvalidAuthFormat  = errors.New("invalid authentication format")
 	ErrInvalidToken       = errors.New("invalid or expired token")
 	ErrInvalidTokenClaims = errors.New("invalid token claims")
+	ErrAdminRequired      = errors.New("administrator privileges required")
+	ErrTokenRevoked       = errors.New("token has been revoked")
 )
 
 // JWTAuth JWT authentication middleware
 // Verify the Bearer token in the request header and extract the user ID
-func JWTAuth(config *model.Config) gin.HandlerFunc {
+func JWTAuth(cfg *model.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Check authorization header
 		authHeader := c.GetHeader("Authorization")
@@ -47,7 +51,7 @@ func JWTAuth(config *model.Config) gin.HandlerFunc {
 			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 				return nil, ErrInvalidToken
 			}
-			return []byte(config.JWT.Secret), nil
+			return config.JWTSecret(cfg), nil
 		})
 
 		// Handle invalid token cases
@@ -70,8 +74,16 @@ func JWTAuth(config *model.Config) gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens revoked by logout
+		jti, _ := claims["jti"].(string)
+		if jti != "" && service.IsTokenRevoked(jti) {
+			abortWithError(c, http.StatusUnauthorized, ErrTokenRevoked)
+			return
+		}
+
 		// Verify expiration time
 		if exp, ok := claims["exp"].(float64); ok {
+			c.Set("token_exp", int64(exp))
 			expTime := time.Unix(int64(exp), 0)
 			if time.Now().After(expTime) {
 				abortWithError(c, http.StatusUnauthorized, errors.New("token expired"))
@@ -86,14 +98,29 @@ func JWTAuth(config *model.Config) gin.HandlerFunc {
 			return
 		}
 
-		// Set user ID to context
+		// Set user ID and token ID to context
 		c.Set("user_id", int64(userID))
+		c.Set("token_id", jti)
 
 		// Continue processing request
 		c.Next()
 	}
 }
 
+// AdminOnly Admin authorization middleware
+// Must be used after JWTAuth, rejects users other than the administrator
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := c.Get("user_id")
+		if !ok || userID.(int64) != model.AdminUserID {
+			abortWithError(c, http.StatusForbidden, ErrAdminRequired)
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // abortWithError Aborts request and returns error response
 func abortWithError(c *gin.Context, status int, err error) {
 	logger.Warn("JWT authentication failed: %v", err)
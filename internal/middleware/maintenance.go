@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// maintenanceMode Whether write requests are currently rejected
+var maintenanceMode atomic.Bool
+
+// SetMaintenanceMode Enables or disables maintenance mode at runtime
+func SetMaintenanceMode(enabled bool) {
+	maintenanceMode.Store(enabled)
+}
+
+// MaintenanceMode Reports whether maintenance mode is enabled
+func MaintenanceMode() bool {
+	return maintenanceMode.Load()
+}
+
+// Maintenance Read-only mode middleware
+// While maintenance mode is enabled, every request other than GET/HEAD/OPTIONS is rejected with 503,
+// except for the given paths (login and the maintenance toggle itself)
+func Maintenance(exemptPaths ...string) gin.HandlerFunc {
+	exempt := make(map[string]struct{}, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if !maintenanceMode.Load() {
+			c.Next()
+			return
+		}
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		if _, ok := exempt[c.Request.URL.Path]; ok {
+			c.Next()
+			return
+		}
+
+		abortMaintenance(c)
+	}
+}
+
+// MaintenanceBlock Rejects the route with 503 while maintenance mode is enabled, whatever its method
+// Used for GET routes that write, such as fetching subscription content
+func MaintenanceBlock() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maintenanceMode.Load() {
+			abortMaintenance(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// abortMaintenance Responds with 503 because maintenance mode is enabled
+func abortMaintenance(c *gin.Context) {
+	c.AbortWithStatusJSON(http.StatusServiceUnavailable, model.StandardResponse{
+		Code:    http.StatusServiceUnavailable,
+		Message: "Service is in maintenance mode, write operations are disabled",
+		Data:    nil,
+	})
+}
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// WithTimeout Per-route timeout middleware
+// Puts a deadline of d on the request context and answers 504 when it is exceeded. The handler runs
+// to completion before the 504 is sent, so handlers must derive their contexts from the request context
+// for the deadline to interrupt them. d <= 0 disables the timeout.
+func WithTimeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if d <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		// The response is held back until the handler returns so a late response can still be replaced
+		writer := newTimeoutWriter(c.Writer)
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			c.JSON(http.StatusGatewayTimeout, model.StandardResponse{
+				Code:    http.StatusGatewayTimeout,
+				Message: "Request timed out",
+				Data:    nil,
+			})
+			return
+		}
+
+		writer.flush()
+	}
+}
+
+// RouteTimeout Applies the timeout configured for groupPath in server.route_timeouts
+// The timeout only cancels the request context, a handler that ignores it keeps the client
+// waiting until it returns and only then is answered with 504
+func RouteTimeout(cfg *model.Config, groupPath string) gin.HandlerFunc {
+	return WithTimeout(time.Duration(cfg.Server.RouteTimeouts[groupPath]) * time.Second)
+}
+
+// timeoutWriter Buffers the status, headers and body until the timeout middleware decides what to send
+// Nothing reaches the underlying writer before flush, including through WriteHeaderNow and Flush
+type timeoutWriter struct {
+	gin.ResponseWriter
+	header      http.Header
+	status      int
+	wroteHeader bool
+	buf         bytes.Buffer
+}
+
+// newTimeoutWriter Wraps w, starting from the headers and status already set on it
+func newTimeoutWriter(w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{
+		ResponseWriter: w,
+		header:         w.Header().Clone(),
+		status:         w.Status(),
+	}
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if code > 0 && !w.wroteHeader {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.wroteHeader = true
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.wroteHeader = true
+	return w.buf.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.wroteHeader = true
+	return w.buf.WriteString(s)
+}
+
+// Flush Marks the headers as written, the buffered response is only sent by flush
+func (w *timeoutWriter) Flush() {
+	w.wroteHeader = true
+}
+
+func (w *timeoutWriter) Status() int {
+	return w.status
+}
+
+// Size Returns the number of buffered body bytes, or -1 when nothing has been written yet
+func (w *timeoutWriter) Size() int {
+	if !w.wroteHeader {
+		return -1
+	}
+	return w.buf.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	return w.wroteHeader
+}
+
+// flush Sends the buffered headers, status and body
+func (w *timeoutWriter) flush() {
+	dst := w.ResponseWriter.Header()
+	for key := range dst {
+		delete(dst, key)
+	}
+	for key, values := range w.header {
+		dst[key] = values
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.buf.Len() > 0 {
+		w.ResponseWriter.Write(w.buf.Bytes())
+	} else if w.wroteHeader {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// newTimeoutEngine Serves GET /slow, which answers after delay or when the request context ends, behind WithTimeout(d)
+func newTimeoutEngine(d, delay time.Duration) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.GET("/slow", WithTimeout(d), func(c *gin.Context) {
+		select {
+		case <-time.After(delay):
+		case <-c.Request.Context().Done():
+		}
+		c.JSON(http.StatusOK, model.SuccessResponse{Code: http.StatusOK, Message: "Success"})
+	})
+	return engine
+}
+
+func TestWithTimeout(t *testing.T) {
+	tests := []struct {
+		name       string
+		timeout    time.Duration
+		delay      time.Duration
+		wantStatus int
+		wantMsg    string
+	}{
+		{"slow handler", 20 * time.Millisecond, time.Second, http.StatusGatewayTimeout, "Request timed out"},
+		{"fast handler", time.Second, 0, http.StatusOK, "Success"},
+		{"disabled", 0, 30 * time.Millisecond, http.StatusOK, "Success"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := newTimeoutEngine(tt.timeout, tt.delay)
+
+			start := time.Now()
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+			if elapsed := time.Since(start); elapsed >= time.Second {
+				t.Errorf("request took %v, want the deadline to interrupt the handler", elapsed)
+			}
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			var resp model.StandardResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("failed to decode response %q: %v", w.Body.String(), err)
+			}
+			if resp.Message != tt.wantMsg || resp.Code != tt.wantStatus {
+				t.Errorf("response = %+v, want code %d and message %q", resp, tt.wantStatus, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestWithTimeoutBuffersHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		handler    gin.HandlerFunc
+		wantStatus int
+		wantHeader string
+	}{
+		{"abort after deadline", func(c *gin.Context) {
+			c.Header("X-Handler", "late")
+			<-c.Request.Context().Done()
+			c.AbortWithStatus(http.StatusNoContent)
+		}, http.StatusGatewayTimeout, ""},
+		{"flush after deadline", func(c *gin.Context) {
+			c.Header("X-Handler", "late")
+			c.Status(http.StatusAccepted)
+			c.Writer.Flush()
+			<-c.Request.Context().Done()
+		}, http.StatusGatewayTimeout, ""},
+		{"abort in time", func(c *gin.Context) {
+			c.Header("X-Handler", "ok")
+			c.AbortWithStatus(http.StatusNoContent)
+		}, http.StatusNoContent, "ok"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := gin.New()
+			engine.GET("/", WithTimeout(20*time.Millisecond), tt.handler)
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("X-Handler"); got != tt.wantHeader {
+				t.Errorf("X-Handler = %q, want %q", got, tt.wantHeader)
+			}
+		})
+	}
+}
+
+func TestTimeoutWriterSize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var size int
+	var written bool
+	engine := gin.New()
+	engine.GET("/", WithTimeout(time.Second), func(c *gin.Context) {
+		c.String(http.StatusOK, "hello")
+		size, written = c.Writer.Size(), c.Writer.Written()
+	})
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if size != len("hello") || !written {
+		t.Errorf("size, written = %d, %v while buffered, want %d, true", size, written, len("hello"))
+	}
+	if w.Body.String() != "hello" {
+		t.Errorf("body = %q, want %q", w.Body.String(), "hello")
+	}
+}
@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+)
+
+// Audit actions recorded for user operations
+const (
+	AuditUserLogin       = "user.login"
+	AuditUserLoginFailed = "user.login_failed"
+	AuditUserLogout      = "user.logout"
+	AuditUserUpdate      = "user.update"
+	AuditUserPassword    = "user.password_change"
+	AuditSubCreate       = "sub.create"
+	AuditSubUpdate       = "sub.update"
+	AuditSubDelete       = "sub.delete"
+	AuditSubReorder      = "sub.reorder"
+	AuditSubSnapshot     = "sub.snapshot"
+	AuditSubLoadSnapshot = "sub.load_snapshot"
+	AuditSubPin          = "sub.pin"
+	AuditSubUnpin        = "sub.unpin"
+	AuditJWTRotate       = "system.jwt_rotate"
+	AuditMaintenance     = "system.maintenance"
+	AuditMigrate         = "system.migrate"
+	AuditDBPool          = "system.db_pool"
+)
+
+// AuditLog Audit log entry
+type AuditLog struct {
+	ID        int64     `json:"id" example:"1"`
+	UserID    int64     `json:"user_id" example:"1"`
+	Action    string    `json:"action" example:"sub.delete"`
+	Target    string    `json:"target" example:"sub:1"`
+	CreatedAt time.Time `json:"created_at" example:"2024-01-01T00:00:00Z"`
+}
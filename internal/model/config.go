@@ -2,14 +2,77 @@ package model
 
 type Config struct {
 	Server struct {
-		Port int    `json:"port"`
-		Host string `json:"host"`
+		Port            int    `json:"port"`
+		Host            string `json:"host"`
+		MaintenanceMode bool   `json:"maintenance_mode"`
+		APIOnly         bool   `json:"api_only"`
+		// AdminAllowedCIDRs IPs or CIDRs allowed to reach admin endpoints, empty allows all
+		AdminAllowedCIDRs []string `json:"admin_allowed_cidrs"`
+		// RedirectTrailingSlash Redirects /path/ to /path (and back) when only the other form is routed
+		RedirectTrailingSlash bool `json:"redirect_trailing_slash"`
+		// CaseInsensitiveRoutes Redirects paths that only differ in case or extra slashes to the routed path
+		CaseInsensitiveRoutes bool `json:"case_insensitive_routes"`
+		// RouteTimeouts Handler timeout in seconds per route group path, e.g. {"/api/sub": 60}, requests over it get 504
+		RouteTimeouts map[string]int `json:"route_timeouts"`
+		// MaxConcurrentPerIP Requests a single client IP may have in flight, 0 disables the limit
+		MaxConcurrentPerIP int `json:"max_concurrent_per_ip"`
+		// MaxRequestTimeoutSeconds Upper bound for the X-Timeout-Seconds request header, 0 ignores the header
+		MaxRequestTimeoutSeconds int `json:"max_request_timeout_seconds"`
+		// LogBodies Logs request and response bodies (size-capped, secrets redacted) while the log level is debug
+		LogBodies bool `json:"log_bodies"`
+		// TLS Serves HTTPS when both files are set, certificates are reloaded when the files change
+		TLS struct {
+			CertFile string `json:"cert_file"`
+			KeyFile  string `json:"key_file"`
+			// AutoCert Obtains certificates from Let's Encrypt for Domains, port 80 is bound for the HTTP-01 challenge
+			AutoCert struct {
+				Domains  []string `json:"domains"`
+				CacheDir string   `json:"cache_dir"`
+				Email    string   `json:"email"`
+			} `json:"auto_cert"`
+		} `json:"tls"`
 	} `json:"server"`
 	Database struct {
-		Path string `json:"path"`
+		Path        string `json:"path"`
+		AutoMigrate bool   `json:"auto_migrate"`
+		// MaintenanceIntervalHours Interval of audit log pruning and vacuuming, 0 disables maintenance
+		MaintenanceIntervalHours int `json:"maintenance_interval_hours"`
+		// AuditRetentionDays Audit log entries older than this are deleted during maintenance, 0 keeps them forever
+		AuditRetentionDays int `json:"audit_retention_days"`
 	} `json:"database"`
 	JWT struct {
-		Secret    string `json:"secret"`
-		ExpiresIn int    `json:"expires_in"`
+		Secret        string `json:"secret"`
+		ExpiresIn     int    `json:"expires_in"`
+		AllowInsecure bool   `json:"allow_insecure"`
 	} `json:"jwt"`
+	Password struct {
+		// Algorithm Hash used for new passwords, bcrypt or argon2id. Hashes of the other algorithm still verify
+		// and are rehashed on the next successful login
+		Algorithm string `json:"algorithm"`
+		// BcryptCost Work factor of new bcrypt hashes, 0 uses bcrypt's default. Hashes with another cost are
+		// rehashed on the next successful login
+		BcryptCost int `json:"bcrypt_cost"`
+	} `json:"password"`
+	Fetcher struct {
+		MaxConcurrentPerHost int `json:"max_concurrent_per_host"`
+		// MaxRedirects Redirect chains are stopped once they reach this many requests, 0 disables redirects
+		MaxRedirects int `json:"max_redirects"`
+		// TimeoutSeconds Overall limit for a fetch including reading the body
+		TimeoutSeconds int `json:"timeout_seconds"`
+		// DialTimeoutSeconds Limit for establishing the TCP connection
+		DialTimeoutSeconds int `json:"dial_timeout_seconds"`
+		// TLSHandshakeTimeoutSeconds Limit for the TLS handshake
+		TLSHandshakeTimeoutSeconds int `json:"tls_handshake_timeout_seconds"`
+		// KeepPreviousOnEmpty Rejects empty fetch results so the previously stored content is kept
+		KeepPreviousOnEmpty bool `json:"keep_previous_on_empty"`
+	} `json:"fetcher"`
+	Scheduler struct {
+		DefaultCron string `json:"default_cron"`
+		// ValidateOnStartup Checks the cron of every stored sub at startup and reports invalid ones
+		ValidateOnStartup bool `json:"validate_on_startup"`
+	} `json:"scheduler"`
+	ContentStore struct {
+		// MaxAgeSeconds Cached content of unpinned subs not fetched for this long is evicted, 0 disables the sweep
+		MaxAgeSeconds int `json:"max_age_seconds"`
+	} `json:"content_store"`
 }
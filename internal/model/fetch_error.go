@@ -0,0 +1,42 @@
+package model
+
+import "fmt"
+
+// FetchErrorKind Category of a failed subscription fetch
+type FetchErrorKind string
+
+const (
+	// FetchErrorDNS The subscription host could not be resolved
+	FetchErrorDNS FetchErrorKind = "dns"
+	// FetchErrorConnect Connecting, the TLS handshake or following redirects failed
+	FetchErrorConnect FetchErrorKind = "connect"
+	// FetchErrorTimeout The fetch did not finish within its timeout
+	FetchErrorTimeout FetchErrorKind = "timeout"
+	// FetchErrorStatus The server answered with a non-200 status
+	FetchErrorStatus FetchErrorKind = "status"
+	// FetchErrorBody Reading the response body failed
+	FetchErrorBody FetchErrorKind = "body"
+)
+
+// FetchError Failed subscription fetch
+// errors.Is matches both ErrFetchFailed and the underlying cause
+type FetchError struct {
+	Kind FetchErrorKind
+	// StatusCode Response status for FetchErrorStatus
+	StatusCode int
+	Err        error
+}
+
+func (e *FetchError) Error() string {
+	if e.Kind == FetchErrorStatus {
+		return fmt.Sprintf("%s: unexpected response status %d", ErrFetchFailed, e.StatusCode)
+	}
+	return fmt.Sprintf("%s (%s): %v", ErrFetchFailed, e.Kind, e.Err)
+}
+
+func (e *FetchError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{ErrFetchFailed}
+	}
+	return []error{ErrFetchFailed, e.Err}
+}
@@ -0,0 +1,22 @@
+package model
+
+// PagedResponse Offset paginated list with the total number of matching items
+type PagedResponse[T any] struct {
+	Items  []T `json:"items"`
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// NewPagedResponse Builds a paged response, a nil items slice is encoded as an empty list
+func NewPagedResponse[T any](items []T, total, limit, offset int) PagedResponse[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return PagedResponse[T]{
+		Items:  items,
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPagedResponseJSON(t *testing.T) {
+	type item struct {
+		ID int64 `json:"id"`
+	}
+
+	tests := []struct {
+		name string
+		page PagedResponse[item]
+		want string
+	}{
+		{
+			name: "items",
+			page: NewPagedResponse([]item{{ID: 3}, {ID: 4}}, 10, 2, 2),
+			want: `{"items":[{"id":3},{"id":4}],"total":10,"limit":2,"offset":2}`,
+		},
+		{
+			name: "nil items",
+			page: NewPagedResponse[item](nil, 0, 20, 0),
+			want: `{"items":[],"total":0,"limit":20,"offset":0}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.page)
+			if err != nil {
+				t.Fatalf("Marshal error = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("JSON = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"errors"
 	"time"
 )
@@ -11,6 +12,8 @@ var (
 	ErrFetchFailed   = errors.New("failed to fetch subscription data")
 	ErrInvalidSubURL = errors.New("invalid subscription URL")
 	ErrParsingFailed = errors.New("failed to parse subscription content")
+	ErrEmptyContent  = errors.New("subscription returned empty content")
+	ErrContentReject = errors.New("subscription content does not match success pattern")
 )
 
 // Sub represents a subscription entry
@@ -25,4 +28,41 @@ type Sub struct {
 	AliveNodes int        `json:"alive_nodes"`
 	Cron       string     `json:"cron,omitempty"`
 	AutoUpdate bool       `json:"auto_update"`
+	SortOrder  int        `json:"sort_order"`
+	// URLVars Values substituted into {{.Name}} placeholders of URL at fetch time
+	URLVars URLVars `json:"url_vars,omitempty"`
+	// FetchTimeoutSeconds Overrides the global fetch timeout when greater than zero
+	FetchTimeoutSeconds int `json:"fetch_timeout_seconds"`
+	// ContentSize Byte size of the content from the last successful fetch
+	ContentSize int64 `json:"content_size"`
+	// Remark Free-form user note describing the subscription
+	Remark string `json:"remark"`
+	// SuccessPattern Regex fetched content must match for the fetch to count as successful
+	SuccessPattern string `json:"success_pattern,omitempty"`
+	// Pinned Fetches still run but keep the stored content until the sub is unpinned
+	Pinned bool `json:"pinned"`
+	// FetchMethod HTTP method used to fetch the subscription, GET or POST
+	FetchMethod string `json:"fetch_method"`
+	// FetchBody Request body sent with POST fetches
+	FetchBody string `json:"fetch_body,omitempty"`
+}
+
+// RedactedURLVar Placeholder returned instead of URL variable values
+const RedactedURLVar = "***"
+
+// URLVars Template variables of a subscription URL, values usually hold tokens
+// Values are masked when encoded to JSON, so responses only reveal which variables are set
+type URLVars map[string]string
+
+// MarshalJSON Encodes the variable names with masked values
+func (v URLVars) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+
+	masked := make(map[string]string, len(v))
+	for name := range v {
+		masked[name] = RedactedURLVar
+	}
+	return json.Marshal(masked)
 }
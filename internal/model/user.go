@@ -4,6 +4,15 @@ import (
 	"time"
 )
 
+// AdminUserID ID of the built-in administrator account
+const AdminUserID int64 = 1
+
+// Password hashing algorithms accepted in password.algorithm
+const (
+	PasswordHashBcrypt   = "bcrypt"
+	PasswordHashArgon2id = "argon2id"
+)
+
 // User User model
 type User struct {
 	ID        int64     `json:"id" example:"1"`
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/model"
+)
+
+// AuditRepository Audit log data access interface
+type AuditRepository interface {
+	// Create Insert an audit log entry
+	Create(ctx context.Context, entry *model.AuditLog) error
+	// List Get audit log entries newest first, along with the total count
+	List(ctx context.Context, limit, offset int) ([]*model.AuditLog, int, error)
+	// DeleteBefore Remove entries created before cutoff, returns the number removed
+	DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// SQLAuditRepository SQL-based audit log repository implementation
+type SQLAuditRepository struct {
+	db *sql.DB
+}
+
+// NewAuditRepository Create new audit log repository
+func NewAuditRepository(db *sql.DB) AuditRepository {
+	return &SQLAuditRepository{db: db}
+}
+
+// Create Insert an audit log entry
+func (r *SQLAuditRepository) Create(ctx context.Context, entry *model.AuditLog) error {
+	now := time.Now().Local().Format(time.RFC3339)
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO audit_log (user_id, action, target, created_at) 
+		 VALUES (?, ?, ?, ?)`,
+		entry.UserID,
+		entry.Action,
+		entry.Target,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert ID: %w", err)
+	}
+
+	entry.ID = id
+	entry.CreatedAt, _ = time.Parse(time.RFC3339, now)
+
+	return nil
+}
+
+// List Get audit log entries newest first, along with the total count
+func (r *SQLAuditRepository) List(ctx context.Context, limit, offset int) ([]*model.AuditLog, int, error) {
+	entries, total, err := queryPage(ctx, r.db,
+		"SELECT COUNT(*) FROM audit_log",
+		`SELECT id, user_id, action, target, created_at
+		 FROM audit_log
+		 ORDER BY id DESC`,
+		limit,
+		offset,
+		scanAuditLog,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+
+	return entries, total, nil
+}
+
+// DeleteBefore Remove entries created before cutoff, returns the number removed
+func (r *SQLAuditRepository) DeleteBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM audit_log WHERE created_at < ?",
+		cutoff.Local().Format(time.RFC3339),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune audit logs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// scanAuditLog Scans a single audit log row
+func scanAuditLog(row rowScanner) (*model.AuditLog, error) {
+	entry := &model.AuditLog{}
+	var createdAt string
+
+	if err := row.Scan(&entry.ID, &entry.UserID, &entry.Action, &entry.Target, &createdAt); err != nil {
+		return nil, fmt.Errorf("failed to scan audit log row: %w", err)
+	}
+
+	var err error
+	if entry.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/model"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "bestsub-repository-test")
+	if err != nil {
+		panic(err)
+	}
+
+	dbConfig := database.DefaultConfig(filepath.Join(dir, "test.db"))
+	dbConfig.BcryptCost = bcrypt.MinCost
+	if err := database.InitDatabaseWithConfig(dbConfig); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+
+	database.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// resetSubs Empties the subs table
+func resetSubs(t *testing.T) {
+	t.Helper()
+
+	if _, err := database.DB.Exec("DELETE FROM subs"); err != nil {
+		t.Fatalf("failed to empty subs: %v", err)
+	}
+}
+
+// createSubs Creates one sub per URL and returns them in creation order
+func createSubs(t *testing.T, repo SubRepository, urls ...string) []*model.Sub {
+	t.Helper()
+
+	subs := make([]*model.Sub, 0, len(urls))
+	for _, u := range urls {
+		sub := &model.Sub{URL: u, Cron: "0 */1 * * *"}
+		if err := repo.Create(context.Background(), sub); err != nil {
+			t.Fatalf("failed to create sub %s: %v", u, err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// subIDs Returns the IDs of subs in order
+func subIDs(subs []*model.Sub) []int64 {
+	ids := make([]int64, len(subs))
+	for i, sub := range subs {
+		ids[i] = sub.ID
+	}
+	return ids
+}
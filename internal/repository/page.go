@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// queryPage Runs countQuery for the total and query with LIMIT/OFFSET appended for one page of rows
+// args are passed to both queries, scan converts a single row
+func queryPage[T any](ctx context.Context, db *sql.DB, countQuery, query string, limit, offset int, scan func(rowScanner) (T, error), args ...any) ([]T, int, error) {
+	var total int
+	if err := db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count rows: %w", err)
+	}
+
+	pageArgs := append(append([]any{}, args...), limit, offset)
+	rows, err := db.QueryContext(ctx, query+" LIMIT ? OFFSET ?", pageArgs...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query page: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]T, 0)
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating rows: %w", err)
+	}
+
+	return items, total, nil
+}
@@ -3,7 +3,9 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/bestruirui/bestsub/internal/database"
@@ -15,13 +17,19 @@ type SubRepository interface {
 	GetByID(ctx context.Context, id int64) (*model.Sub, error)
 	GetAll(ctx context.Context) ([]*model.Sub, error)
 	GetAllAutoUpdateSubs(ctx context.Context) ([]*model.Sub, error)
+	GetByMinAlive(ctx context.Context, minAlive int) ([]*model.Sub, error)
+	GetPageAfter(ctx context.Context, afterID int64, limit, minAlive int) ([]*model.Sub, error)
 	Create(ctx context.Context, sub *model.Sub) error
 	Update(ctx context.Context, sub *model.Sub) error
 	Delete(ctx context.Context, id int64) error
 	UpdateStats(ctx context.Context, id int64, totalNodes, aliveNodes int) error
 	UpdateLastCheck(ctx context.Context, id int64) error
-	UpdateLastFetch(ctx context.Context, id int64) error
+	UpdateLastFetch(ctx context.Context, id int64, contentSize int64) error
 	UpdateCronSettings(ctx context.Context, id int64, cron string, autoUpdate bool) error
+	SetPinned(ctx context.Context, id int64, pinned bool, content string) error
+	GetPinnedContent(ctx context.Context, id int64) (string, bool, error)
+	Reorder(ctx context.Context, ids []int64) error
+	TotalContentSize(ctx context.Context) (int64, error)
 }
 
 // SQLSubRepository SQL-based sub storage repository implementation
@@ -34,18 +42,20 @@ func NewSubRepository(db *sql.DB) SubRepository {
 	return &SQLSubRepository{db: db}
 }
 
-// GetByID Get sub by ID
-func (r *SQLSubRepository) GetByID(ctx context.Context, id int64) (*model.Sub, error) {
-	query := `SELECT id, url, last_check, last_fetch, created_at, updated_at, total_nodes, alive_nodes, cron, auto_update
-	          FROM subs 
-			  WHERE id = ?`
+// subColumns Columns selected for every sub query, in scanSub order
+const subColumns = `id, url, last_check, last_fetch, created_at, updated_at, total_nodes, alive_nodes, cron, auto_update, sort_order, url_vars, fetch_timeout_seconds, content_size, remark, success_pattern, pinned, fetch_method, fetch_body`
 
-	row := r.db.QueryRowContext(ctx, query, id)
+// rowScanner Common interface of *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
 
+// scanSub Scans a single sub row selected with subColumns
+func scanSub(row rowScanner) (*model.Sub, error) {
 	sub := &model.Sub{}
 	var lastCheck, lastFetch sql.NullTime
-	var createdAt, updatedAt string
-	var autoUpdate int
+	var createdAt, updatedAt, urlVars string
+	var autoUpdate, pinned int
 
 	err := row.Scan(
 		&sub.ID,
@@ -58,13 +68,24 @@ func (r *SQLSubRepository) GetByID(ctx context.Context, id int64) (*model.Sub, e
 		&sub.AliveNodes,
 		&sub.Cron,
 		&autoUpdate,
+		&sub.SortOrder,
+		&urlVars,
+		&sub.FetchTimeoutSeconds,
+		&sub.ContentSize,
+		&sub.Remark,
+		&sub.SuccessPattern,
+		&pinned,
+		&sub.FetchMethod,
+		&sub.FetchBody,
 	)
-
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, model.ErrSubNotFound
+		return nil, err
+	}
+
+	if urlVars != "" {
+		if err := json.Unmarshal([]byte(urlVars), &sub.URLVars); err != nil {
+			return nil, fmt.Errorf("failed to parse url_vars: %w", err)
 		}
-		return nil, fmt.Errorf("failed to get sub by ID: %w", err)
 	}
 
 	if lastCheck.Valid {
@@ -75,8 +96,11 @@ func (r *SQLSubRepository) GetByID(ctx context.Context, id int64) (*model.Sub, e
 		sub.LastFetch = &lastFetch.Time
 	}
 
+	// 将SQLite的整数布尔值转换为Go布尔值
 	sub.AutoUpdate = autoUpdate == 1
+	sub.Pinned = pinned == 1
 
+	// Parse timestamps
 	if sub.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
 		return nil, fmt.Errorf("failed to parse created_at: %w", err)
 	}
@@ -88,67 +112,69 @@ func (r *SQLSubRepository) GetByID(ctx context.Context, id int64) (*model.Sub, e
 	return sub, nil
 }
 
-// GetAll Get all subs
-func (r *SQLSubRepository) GetAll(ctx context.Context) ([]*model.Sub, error) {
-	query := `SELECT id, url, last_check, last_fetch, created_at, updated_at, total_nodes, alive_nodes, cron, auto_update
-	          FROM subs 
-			  ORDER BY id ASC`
+// encodeURLVars Serializes URL variables, map keys are sorted so equal maps encode identically
+func encodeURLVars(vars map[string]string) (string, error) {
+	if len(vars) == 0 {
+		return "", nil
+	}
 
-	rows, err := r.db.QueryContext(ctx, query)
+	data, err := json.Marshal(vars)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all subs: %w", err)
+		return "", fmt.Errorf("failed to encode url_vars: %w", err)
+	}
+	return string(data), nil
+}
+
+// querySubs Runs a sub query and scans all resulting rows
+func (r *SQLSubRepository) querySubs(ctx context.Context, query string, args ...any) ([]*model.Sub, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
 	}
 	defer rows.Close()
 
 	var subs []*model.Sub
 	for rows.Next() {
-		sub := &model.Sub{}
-		var lastCheck, lastFetch sql.NullTime
-		var createdAt, updatedAt string
-		var autoUpdate int
-
-		err := rows.Scan(
-			&sub.ID,
-			&sub.URL,
-			&lastCheck,
-			&lastFetch,
-			&createdAt,
-			&updatedAt,
-			&sub.TotalNodes,
-			&sub.AliveNodes,
-			&sub.Cron,
-			&autoUpdate,
-		)
-
+		sub, err := scanSub(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan sub row: %w", err)
 		}
+		subs = append(subs, sub)
+	}
 
-		if lastCheck.Valid {
-			sub.LastCheck = &lastCheck.Time
-		}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sub rows: %w", err)
+	}
 
-		if lastFetch.Valid {
-			sub.LastFetch = &lastFetch.Time
-		}
+	return subs, nil
+}
 
-		// 将SQLite的整数布尔值转换为Go布尔值
-		sub.AutoUpdate = autoUpdate == 1
+// GetByID Get sub by ID
+func (r *SQLSubRepository) GetByID(ctx context.Context, id int64) (*model.Sub, error) {
+	query := `SELECT ` + subColumns + `
+	          FROM subs 
+			  WHERE id = ?`
 
-		// Parse timestamps
-		if sub.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
-			return nil, fmt.Errorf("failed to parse created_at: %w", err)
+	sub, err := scanSub(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, model.ErrSubNotFound
 		}
+		return nil, fmt.Errorf("failed to get sub by ID: %w", err)
+	}
 
-		if sub.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt); err != nil {
-			return nil, fmt.Errorf("failed to parse updated_at: %w", err)
-		}
+	return sub, nil
+}
 
-		subs = append(subs, sub)
-	}
+// GetAll Get all subs in display order
+func (r *SQLSubRepository) GetAll(ctx context.Context) ([]*model.Sub, error) {
+	query := `SELECT ` + subColumns + `
+	          FROM subs 
+			  ORDER BY sort_order ASC, id ASC`
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating sub rows: %w", err)
+	subs, err := r.querySubs(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all subs: %w", err)
 	}
 
 	return subs, nil
@@ -156,66 +182,46 @@ func (r *SQLSubRepository) GetAll(ctx context.Context) ([]*model.Sub, error) {
 
 // GetAllAutoUpdateSubs 获取所有启用了自动更新的订阅
 func (r *SQLSubRepository) GetAllAutoUpdateSubs(ctx context.Context) ([]*model.Sub, error) {
-	query := `SELECT id, url, last_check, last_fetch, created_at, updated_at, total_nodes, alive_nodes, cron, auto_update
+	query := `SELECT ` + subColumns + `
 	          FROM subs 
 			  WHERE auto_update = 1
-			  ORDER BY id ASC`
+			  ORDER BY sort_order ASC, id ASC`
 
-	rows, err := r.db.QueryContext(ctx, query)
+	subs, err := r.querySubs(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get auto-update subs: %w", err)
 	}
-	defer rows.Close()
-
-	var subs []*model.Sub
-	for rows.Next() {
-		sub := &model.Sub{}
-		var lastCheck, lastFetch sql.NullTime
-		var createdAt, updatedAt string
-		var autoUpdate int
-
-		err := rows.Scan(
-			&sub.ID,
-			&sub.URL,
-			&lastCheck,
-			&lastFetch,
-			&createdAt,
-			&updatedAt,
-			&sub.TotalNodes,
-			&sub.AliveNodes,
-			&sub.Cron,
-			&autoUpdate,
-		)
-
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan sub row: %w", err)
-		}
-
-		if lastCheck.Valid {
-			sub.LastCheck = &lastCheck.Time
-		}
 
-		if lastFetch.Valid {
-			sub.LastFetch = &lastFetch.Time
-		}
+	return subs, nil
+}
 
-		// 将SQLite的整数布尔值转换为Go布尔值
-		sub.AutoUpdate = autoUpdate == 1
+// GetByMinAlive Get subs with at least minAlive alive nodes
+func (r *SQLSubRepository) GetByMinAlive(ctx context.Context, minAlive int) ([]*model.Sub, error) {
+	query := `SELECT ` + subColumns + `
+	          FROM subs 
+			  WHERE alive_nodes >= ?
+			  ORDER BY sort_order ASC, id ASC`
 
-		// Parse timestamps
-		if sub.CreatedAt, err = time.Parse(time.RFC3339, createdAt); err != nil {
-			return nil, fmt.Errorf("failed to parse created_at: %w", err)
-		}
+	subs, err := r.querySubs(ctx, query, minAlive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subs by alive nodes: %w", err)
+	}
 
-		if sub.UpdatedAt, err = time.Parse(time.RFC3339, updatedAt); err != nil {
-			return nil, fmt.Errorf("failed to parse updated_at: %w", err)
-		}
+	return subs, nil
+}
 
-		subs = append(subs, sub)
-	}
+// GetPageAfter Get up to limit subs with an ID greater than afterID in ID order
+// Keyset pagination keeps pages stable and cheap on large tables
+func (r *SQLSubRepository) GetPageAfter(ctx context.Context, afterID int64, limit, minAlive int) ([]*model.Sub, error) {
+	query := `SELECT ` + subColumns + `
+	          FROM subs 
+			  WHERE id > ? AND alive_nodes >= ?
+			  ORDER BY id ASC
+			  LIMIT ?`
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating auto-update sub rows: %w", err)
+	subs, err := r.querySubs(ctx, query, afterID, minAlive, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sub page: %w", err)
 	}
 
 	return subs, nil
@@ -223,12 +229,18 @@ func (r *SQLSubRepository) GetAllAutoUpdateSubs(ctx context.Context) ([]*model.S
 
 // Create Create new sub
 func (r *SQLSubRepository) Create(ctx context.Context, sub *model.Sub) error {
+	urlVars, err := encodeURLVars(sub.URLVars)
+	if err != nil {
+		return err
+	}
+
 	return database.WithTransaction(ctx, func(tx *sql.Tx) error {
-		// Check if sub already exists
+		// Check if sub already exists, templated URLs differ by their variables
 		var exists bool
 		err := tx.QueryRowContext(ctx,
-			"SELECT EXISTS(SELECT 1 FROM subs WHERE url = ?)",
+			"SELECT EXISTS(SELECT 1 FROM subs WHERE url = ? AND url_vars = ?)",
 			sub.URL,
+			urlVars,
 		).Scan(&exists)
 
 		if err != nil {
@@ -245,11 +257,25 @@ func (r *SQLSubRepository) Create(ctx context.Context, sub *model.Sub) error {
 			autoUpdateInt = 1
 		}
 
+		if sub.FetchMethod == "" {
+			sub.FetchMethod = http.MethodGet
+		}
+
+		// New subs are appended after the existing ones
+		var sortOrder int
+		err = tx.QueryRowContext(ctx,
+			"SELECT COALESCE(MAX(sort_order), 0) + 1 FROM subs",
+		).Scan(&sortOrder)
+
+		if err != nil {
+			return fmt.Errorf("failed to get next sort order: %w", err)
+		}
+
 		// Insert new sub
 		now := time.Now().Local().Format(time.RFC3339)
 		result, err := tx.ExecContext(ctx,
-			`INSERT INTO subs (url, last_check, last_fetch, created_at, updated_at, total_nodes, alive_nodes, cron, auto_update) 
-			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			`INSERT INTO subs (url, last_check, last_fetch, created_at, updated_at, total_nodes, alive_nodes, cron, auto_update, sort_order, url_vars, fetch_timeout_seconds, remark, success_pattern, fetch_method, fetch_body) 
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 			sub.URL,
 			sub.LastCheck,
 			sub.LastFetch,
@@ -259,6 +285,13 @@ func (r *SQLSubRepository) Create(ctx context.Context, sub *model.Sub) error {
 			sub.AliveNodes,
 			sub.Cron,
 			autoUpdateInt,
+			sortOrder,
+			urlVars,
+			sub.FetchTimeoutSeconds,
+			sub.Remark,
+			sub.SuccessPattern,
+			sub.FetchMethod,
+			sub.FetchBody,
 		)
 
 		if err != nil {
@@ -272,6 +305,7 @@ func (r *SQLSubRepository) Create(ctx context.Context, sub *model.Sub) error {
 		}
 
 		sub.ID = id
+		sub.SortOrder = sortOrder
 		sub.CreatedAt, _ = time.Parse(time.RFC3339, now)
 		sub.UpdatedAt = sub.CreatedAt
 
@@ -281,6 +315,11 @@ func (r *SQLSubRepository) Create(ctx context.Context, sub *model.Sub) error {
 
 // Update Update sub information
 func (r *SQLSubRepository) Update(ctx context.Context, sub *model.Sub) error {
+	urlVars, err := encodeURLVars(sub.URLVars)
+	if err != nil {
+		return err
+	}
+
 	return database.WithTransaction(ctx, func(tx *sql.Tx) error {
 		// Check if sub exists
 		var exists bool
@@ -307,7 +346,7 @@ func (r *SQLSubRepository) Update(ctx context.Context, sub *model.Sub) error {
 		now := time.Now().Local().Format(time.RFC3339)
 		_, err = tx.ExecContext(ctx,
 			`UPDATE subs 
-			 SET url = ?, last_check = ?, last_fetch = ?, updated_at = ?, total_nodes = ?, alive_nodes = ?, cron = ?, auto_update = ?
+			 SET url = ?, last_check = ?, last_fetch = ?, updated_at = ?, total_nodes = ?, alive_nodes = ?, cron = ?, auto_update = ?, url_vars = ?, fetch_timeout_seconds = ?, remark = ?, success_pattern = ?, fetch_method = ?, fetch_body = ?
 			 WHERE id = ?`,
 			sub.URL,
 			sub.LastCheck,
@@ -317,6 +356,12 @@ func (r *SQLSubRepository) Update(ctx context.Context, sub *model.Sub) error {
 			sub.AliveNodes,
 			sub.Cron,
 			autoUpdateInt,
+			urlVars,
+			sub.FetchTimeoutSeconds,
+			sub.Remark,
+			sub.SuccessPattern,
+			sub.FetchMethod,
+			sub.FetchBody,
 			sub.ID,
 		)
 
@@ -434,8 +479,8 @@ func (r *SQLSubRepository) UpdateLastCheck(ctx context.Context, id int64) error
 	})
 }
 
-// UpdateLastFetch Update last fetch time
-func (r *SQLSubRepository) UpdateLastFetch(ctx context.Context, id int64) error {
+// UpdateLastFetch Update last fetch time and the size of the fetched content
+func (r *SQLSubRepository) UpdateLastFetch(ctx context.Context, id int64, contentSize int64) error {
 	return database.WithTransaction(ctx, func(tx *sql.Tx) error {
 		// Check if sub exists
 		var exists bool
@@ -456,10 +501,11 @@ func (r *SQLSubRepository) UpdateLastFetch(ctx context.Context, id int64) error
 		now := time.Now().Local().Format(time.RFC3339)
 		_, err = tx.ExecContext(ctx,
 			`UPDATE subs 
-			 SET last_fetch = ?, updated_at = ?
+			 SET last_fetch = ?, updated_at = ?, content_size = ?
 			 WHERE id = ?`,
 			now,
 			now,
+			contentSize,
 			id,
 		)
 
@@ -514,3 +560,126 @@ func (r *SQLSubRepository) UpdateCronSettings(ctx context.Context, id int64, cro
 		return nil
 	})
 }
+
+// SetPinned 设置订阅是否固定当前内容
+// Pinning persists content as the pinned copy so it survives restarts, unpinning drops the copy
+func (r *SQLSubRepository) SetPinned(ctx context.Context, id int64, pinned bool, content string) error {
+	pinnedInt := 0
+	var pinnedContent sql.NullString
+	if pinned {
+		pinnedInt = 1
+		pinnedContent = sql.NullString{String: content, Valid: true}
+	}
+
+	now := time.Now().Local().Format(time.RFC3339)
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE subs 
+		 SET pinned = ?, pinned_content = ?, updated_at = ?
+		 WHERE id = ?`,
+		pinnedInt,
+		pinnedContent,
+		now,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update pinned flag: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return model.ErrSubNotFound
+	}
+
+	return nil
+}
+
+// GetPinnedContent 获取订阅持久化的固定内容
+// The bool is false when the sub has no pinned copy, e.g. it was pinned before copies were persisted
+func (r *SQLSubRepository) GetPinnedContent(ctx context.Context, id int64) (string, bool, error) {
+	var content sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT pinned_content FROM subs WHERE id = ?`, id).Scan(&content)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", false, model.ErrSubNotFound
+		}
+		return "", false, fmt.Errorf("failed to get pinned content: %w", err)
+	}
+
+	return content.String, content.Valid, nil
+}
+
+// Reorder 按给定ID顺序更新订阅的排序
+// Subs missing from ids are placed after the listed ones in their current relative order,
+// so every sub ends up with a distinct position
+func (r *SQLSubRepository) Reorder(ctx context.Context, ids []int64) error {
+	return database.WithTransaction(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `SELECT id FROM subs ORDER BY sort_order ASC, id ASC`)
+		if err != nil {
+			return fmt.Errorf("failed to get current order: %w", err)
+		}
+
+		var current []int64
+		existing := make(map[int64]struct{})
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan sub ID: %w", err)
+			}
+			current = append(current, id)
+			existing[id] = struct{}{}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating sub IDs: %w", err)
+		}
+
+		order := make([]int64, 0, len(current))
+		listed := make(map[int64]struct{}, len(ids))
+		for _, id := range ids {
+			if _, ok := existing[id]; !ok {
+				return model.ErrSubNotFound
+			}
+			listed[id] = struct{}{}
+			order = append(order, id)
+		}
+		for _, id := range current {
+			if _, ok := listed[id]; !ok {
+				order = append(order, id)
+			}
+		}
+
+		now := time.Now().Local().Format(time.RFC3339)
+		for i, id := range order {
+			_, err := tx.ExecContext(ctx,
+				`UPDATE subs 
+				 SET sort_order = ?, updated_at = ?
+				 WHERE id = ? AND sort_order != ?`,
+				i+1,
+				now,
+				id,
+				i+1,
+			)
+
+			if err != nil {
+				return fmt.Errorf("failed to update sort order: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// TotalContentSize Sum of the last fetched content size over all subs
+func (r *SQLSubRepository) TotalContentSize(ctx context.Context) (int64, error) {
+	var total int64
+	err := r.db.QueryRowContext(ctx, "SELECT COALESCE(SUM(content_size), 0) FROM subs").Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("failed to sum content size: %w", err)
+	}
+	return total, nil
+}
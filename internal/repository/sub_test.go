@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/model"
+)
+
+func TestReorder(t *testing.T) {
+	resetSubs(t)
+	repo := NewSubRepository(database.DB)
+	ctx := context.Background()
+
+	subs := createSubs(t, repo, "http://a.example/sub", "http://b.example/sub", "http://c.example/sub")
+	a, b, c := subs[0].ID, subs[1].ID, subs[2].ID
+
+	if err := repo.Reorder(ctx, []int64{c, a, b}); err != nil {
+		t.Fatalf("Reorder error = %v", err)
+	}
+
+	all, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll error = %v", err)
+	}
+	if got, want := subIDs(all), []int64{c, a, b}; !slices.Equal(got, want) {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+	for i, sub := range all {
+		if sub.SortOrder != i+1 {
+			t.Errorf("sub %d sort_order = %d, want %d", sub.ID, sub.SortOrder, i+1)
+		}
+	}
+}
+
+func TestReorderPartial(t *testing.T) {
+	resetSubs(t)
+	repo := NewSubRepository(database.DB)
+	ctx := context.Background()
+
+	subs := createSubs(t, repo, "http://a.example/sub", "http://b.example/sub", "http://c.example/sub", "http://d.example/sub")
+	a, b, c, d := subs[0].ID, subs[1].ID, subs[2].ID, subs[3].ID
+
+	// Unlisted subs keep their relative order behind the listed ones
+	if err := repo.Reorder(ctx, []int64{d, b}); err != nil {
+		t.Fatalf("Reorder error = %v", err)
+	}
+
+	all, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll error = %v", err)
+	}
+	if got, want := subIDs(all), []int64{d, b, a, c}; !slices.Equal(got, want) {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+
+	seen := make(map[int]bool)
+	for _, sub := range all {
+		if seen[sub.SortOrder] {
+			t.Errorf("sort_order %d is used by more than one sub", sub.SortOrder)
+		}
+		seen[sub.SortOrder] = true
+	}
+}
+
+func TestReorderUnknownID(t *testing.T) {
+	resetSubs(t)
+	repo := NewSubRepository(database.DB)
+	ctx := context.Background()
+
+	subs := createSubs(t, repo, "http://a.example/sub", "http://b.example/sub")
+
+	err := repo.Reorder(ctx, []int64{subs[1].ID, subs[1].ID + 100})
+	if !errors.Is(err, model.ErrSubNotFound) {
+		t.Fatalf("Reorder error = %v, want ErrSubNotFound", err)
+	}
+
+	// The failed reorder is rolled back
+	all, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatalf("GetAll error = %v", err)
+	}
+	if got, want := subIDs(all), subIDs(subs); !slices.Equal(got, want) {
+		t.Errorf("order = %v, want unchanged %v", got, want)
+	}
+}
+
+func TestGetByMinAlive(t *testing.T) {
+	resetSubs(t)
+	repo := NewSubRepository(database.DB)
+	ctx := context.Background()
+
+	subs := createSubs(t, repo, "http://a.example/sub", "http://b.example/sub", "http://c.example/sub", "http://d.example/sub")
+	alive := []int{0, 3, 10, 5}
+	for i, sub := range subs {
+		if err := repo.UpdateStats(ctx, sub.ID, 10, alive[i]); err != nil {
+			t.Fatalf("UpdateStats error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		minAlive int
+		want     []int64
+	}{
+		{0, subIDs(subs)},
+		{3, []int64{subs[1].ID, subs[2].ID, subs[3].ID}},
+		{5, []int64{subs[2].ID, subs[3].ID}},
+		{10, []int64{subs[2].ID}},
+		{11, nil},
+	}
+
+	for _, tt := range tests {
+		got, err := repo.GetByMinAlive(ctx, tt.minAlive)
+		if err != nil {
+			t.Fatalf("GetByMinAlive(%d) error = %v", tt.minAlive, err)
+		}
+		if ids := subIDs(got); !slices.Equal(ids, tt.want) {
+			t.Errorf("GetByMinAlive(%d) = %v, want %v", tt.minAlive, ids, tt.want)
+		}
+		for _, sub := range got {
+			if sub.AliveNodes < tt.minAlive {
+				t.Errorf("GetByMinAlive(%d) returned sub %d with %d alive nodes", tt.minAlive, sub.ID, sub.AliveNodes)
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// TokenBlacklistRepository Revoked JWT data access interface
+type TokenBlacklistRepository interface {
+	// Add Record a revoked token ID together with its expiry
+	Add(ctx context.Context, jti string, expiresAt time.Time) error
+	// ListActive Get revoked token IDs that have not expired at now, mapped to their expiry
+	ListActive(ctx context.Context, now time.Time) (map[string]time.Time, error)
+	// DeleteExpired Remove entries that expired before now, returns the number removed
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// SQLTokenBlacklistRepository SQL-based token blacklist repository implementation
+type SQLTokenBlacklistRepository struct {
+	db *sql.DB
+}
+
+// NewTokenBlacklistRepository Create new token blacklist repository
+func NewTokenBlacklistRepository(db *sql.DB) TokenBlacklistRepository {
+	return &SQLTokenBlacklistRepository{db: db}
+}
+
+// Add Record a revoked token ID together with its expiry
+func (r *SQLTokenBlacklistRepository) Add(ctx context.Context, jti string, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		"INSERT OR REPLACE INTO token_blacklist (jti, expires_at) VALUES (?, ?)",
+		jti,
+		expiresAt.Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add token to blacklist: %w", err)
+	}
+	return nil
+}
+
+// ListActive Get revoked token IDs that have not expired at now, mapped to their expiry
+func (r *SQLTokenBlacklistRepository) ListActive(ctx context.Context, now time.Time) (map[string]time.Time, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT jti, expires_at FROM token_blacklist WHERE expires_at > ?",
+		now.Unix(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query token blacklist: %w", err)
+	}
+	defer rows.Close()
+
+	revoked := make(map[string]time.Time)
+	for rows.Next() {
+		var jti string
+		var expiresAt int64
+		if err := rows.Scan(&jti, &expiresAt); err != nil {
+			return nil, fmt.Errorf("failed to scan token blacklist row: %w", err)
+		}
+		revoked[jti] = time.Unix(expiresAt, 0)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating token blacklist rows: %w", err)
+	}
+
+	return revoked, nil
+}
+
+// DeleteExpired Remove entries that expired before now, returns the number removed
+func (r *SQLTokenBlacklistRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx,
+		"DELETE FROM token_blacklist WHERE expires_at <= ?",
+		now.Unix(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune token blacklist: %w", err)
+	}
+	return result.RowsAffected()
+}
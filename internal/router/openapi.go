@@ -0,0 +1,113 @@
+package router
+
+import (
+	"path"
+	"strings"
+)
+
+// OpenAPIDocument is a minimal OpenAPI 3 document listing the registered paths.
+type OpenAPIDocument struct {
+	OpenAPI string                                  `json:"openapi"`
+	Info    OpenAPIInfo                             `json:"info"`
+	Paths   map[string]map[string]*OpenAPIOperation `json:"paths"`
+}
+
+// OpenAPIInfo holds the document title and version.
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// OpenAPIOperation describes a single method on a path.
+type OpenAPIOperation struct {
+	Summary    string                     `json:"summary,omitempty"`
+	Tags       []string                   `json:"tags,omitempty"`
+	Parameters []OpenAPIParameter         `json:"parameters,omitempty"`
+	Responses  map[string]OpenAPIResponse `json:"responses"`
+}
+
+// OpenAPIParameter describes a path parameter.
+type OpenAPIParameter struct {
+	Name     string            `json:"name"`
+	In       string            `json:"in"`
+	Required bool              `json:"required"`
+	Schema   map[string]string `json:"schema"`
+}
+
+// OpenAPIResponse describes a response.
+type OpenAPIResponse struct {
+	Description string `json:"description"`
+}
+
+// anyMethods are the methods an ANY route is documented under.
+var anyMethods = []Method{GET, POST, PUT, PATCH, DELETE}
+
+// NewOpenAPIDocument builds an OpenAPI document from the route groups of the given routers.
+// Each group path is used as the tag of its routes and route descriptions become summaries.
+func NewOpenAPIDocument(title, version string, routers ...GroupedRouter) *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    OpenAPIInfo{Title: title, Version: version},
+		Paths:   make(map[string]map[string]*OpenAPIOperation),
+	}
+
+	for _, r := range routers {
+		for _, group := range r.Groups() {
+			for _, route := range group.Routes {
+				doc.addRoute(group.Path, route)
+			}
+		}
+	}
+
+	return doc
+}
+
+// addRoute adds a route of the group at groupPath to the document.
+func (d *OpenAPIDocument) addRoute(groupPath string, route *Route) {
+	openAPIPath, params := toOpenAPIPath(path.Join(groupPath, route.Path))
+
+	methods := []Method{route.Method}
+	if route.Method == ANY {
+		methods = anyMethods
+	}
+
+	operations, ok := d.Paths[openAPIPath]
+	if !ok {
+		operations = make(map[string]*OpenAPIOperation)
+		d.Paths[openAPIPath] = operations
+	}
+
+	for _, method := range methods {
+		operations[strings.ToLower(string(method))] = &OpenAPIOperation{
+			Summary:    route.Description,
+			Tags:       []string{groupPath},
+			Parameters: params,
+			Responses: map[string]OpenAPIResponse{
+				"default": {Description: "Standard response"},
+			},
+		}
+	}
+}
+
+// toOpenAPIPath converts gin path parameters (:id, *path) to OpenAPI templates ({id}, {path}).
+func toOpenAPIPath(ginPath string) (string, []OpenAPIParameter) {
+	segments := strings.Split(ginPath, "/")
+	var params []OpenAPIParameter
+
+	for i, segment := range segments {
+		if len(segment) < 2 || (segment[0] != ':' && segment[0] != '*') {
+			continue
+		}
+
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+		params = append(params, OpenAPIParameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   map[string]string{"type": "string"},
+		})
+	}
+
+	return strings.Join(segments, "/"), params
+}
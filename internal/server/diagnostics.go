@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/logger"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/repository"
+	"github.com/bestruirui/bestsub/internal/service"
+	"github.com/bestruirui/bestsub/internal/validator"
+)
+
+// defaultAdminPassword Password the initial admin account is created with
+const defaultAdminPassword = "admin"
+
+// printDiagnostics Prints a startup summary so operators can confirm configuration at a glance
+// Verbose settings are only printed at debug log level
+func (s *Server) printDiagnostics(serverAddr string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	subRepo := repository.NewSubRepository(database.DB)
+	userRepo := repository.NewUserRepository(database.DB)
+
+	logger.Info("---------- Startup diagnostics ----------")
+	logger.Info("Database:      sqlite3 %s", s.config.Database.Path)
+	logger.Info("Listen:        %s", serverAddr)
+
+	subs, err := subRepo.GetAll(ctx)
+	if err != nil {
+		logger.Warn("Subscriptions: unavailable (%v)", err)
+	} else {
+		autoUpdate := 0
+		for _, sub := range subs {
+			if sub.AutoUpdate {
+				autoUpdate++
+			}
+		}
+		logger.Info("Subscriptions: %d (%d with auto update)", len(subs), autoUpdate)
+
+		if s.config.Scheduler.ValidateOnStartup {
+			checkStoredCrons(subs)
+		}
+	}
+	logger.Info("Scheduler:     default cron %q", s.config.Scheduler.DefaultCron)
+
+	if s.config.Server.MaintenanceMode {
+		logger.Warn("Maintenance mode is enabled, write requests will be rejected")
+	}
+
+	admin, err := userRepo.GetByID(ctx, model.AdminUserID)
+	if err == nil && service.CheckPassword(admin.Password, defaultAdminPassword) {
+		logger.Warn("Admin account %q still uses the default password, change it as soon as possible", admin.Username)
+	}
+
+	logger.Debug("Auto migrate:  %t", s.config.Database.AutoMigrate)
+	logger.Debug("API only:      %t", s.config.Server.APIOnly)
+	logger.Debug("JWT expires:   %dh", s.config.JWT.ExpiresIn)
+	logger.Debug("Fetcher:       timeout %ds, max redirects %d, max %d per host",
+		s.config.Fetcher.TimeoutSeconds, s.config.Fetcher.MaxRedirects, s.config.Fetcher.MaxConcurrentPerHost)
+	if len(s.config.Server.AdminAllowedCIDRs) > 0 {
+		logger.Debug("Admin CIDRs:   %v", s.config.Server.AdminAllowedCIDRs)
+	}
+	logger.Info("-----------------------------------------")
+}
+
+// checkStoredCrons Warns about subs whose stored cron is invalid, e.g. after a manual database edit
+// Such subs get no next run (see /api/sub/schedule) until their cron is fixed
+func checkStoredCrons(subs []*model.Sub) {
+	now := time.Now()
+	invalid := 0
+	for _, sub := range subs {
+		if !sub.AutoUpdate {
+			continue
+		}
+		// NextRuns runs ValidateCron and additionally rejects out of range values
+		if _, err := validator.NextRuns(sub.Cron, now, 1); err != nil {
+			logger.Warn("Subscription %d has an invalid cron %q and will not be scheduled: %v", sub.ID, sub.Cron, err)
+			invalid++
+		}
+	}
+	if invalid > 0 {
+		logger.Warn("%d subscription(s) skipped because of invalid cron expressions", invalid)
+	}
+}
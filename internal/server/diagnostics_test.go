@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/logger"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/repository"
+)
+
+// newDiagnosticsServer Returns a server over the test database with two subs, one auto-updating
+func newDiagnosticsServer(t *testing.T) *Server {
+	t.Helper()
+
+	if _, err := database.DB.Exec("DELETE FROM subs"); err != nil {
+		t.Fatalf("failed to empty subs: %v", err)
+	}
+	repo := repository.NewSubRepository(database.DB)
+	for _, sub := range []*model.Sub{
+		{URL: "http://a.example/sub", Cron: "0 */1 * * *", AutoUpdate: true},
+		{URL: "http://b.example/sub", Cron: "0 */1 * * *"},
+	} {
+		if err := repo.Create(context.Background(), sub); err != nil {
+			t.Fatalf("failed to create sub: %v", err)
+		}
+	}
+
+	cfg := &model.Config{}
+	cfg.Database.Path = "/data/bestsub.db"
+	cfg.Scheduler.DefaultCron = "0 */1 * * *"
+	cfg.Fetcher.TimeoutSeconds = 30
+	cfg.Server.AdminAllowedCIDRs = []string{"10.0.0.0/8"}
+	return &Server{config: cfg}
+}
+
+func TestPrintDiagnostics(t *testing.T) {
+	s := newDiagnosticsServer(t)
+	logs := captureLogs(t, logger.LogLevelInfo)
+
+	s.printDiagnostics("127.0.0.1:8080")
+	out := logs.String()
+
+	for _, line := range []string{
+		"Startup diagnostics",
+		"Database:      sqlite3 /data/bestsub.db",
+		"Listen:        127.0.0.1:8080",
+		"Subscriptions: 2 (1 with auto update)",
+		`Scheduler:     default cron "0 */1 * * *"`,
+		`Admin account "admin" still uses the default password`,
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("diagnostics missing %q:\n%s", line, out)
+		}
+	}
+
+	// Verbose settings need debug level
+	for _, line := range []string{"Auto migrate:", "Fetcher:", "Admin CIDRs:"} {
+		if strings.Contains(out, line) {
+			t.Errorf("diagnostics at info level contain %q:\n%s", line, out)
+		}
+	}
+}
+
+func TestPrintDiagnosticsDebug(t *testing.T) {
+	s := newDiagnosticsServer(t)
+	logs := captureLogs(t, logger.LogLevelDebug)
+
+	s.printDiagnostics("127.0.0.1:8080")
+	out := logs.String()
+
+	for _, line := range []string{
+		"Auto migrate:  false",
+		"Fetcher:       timeout 30s, max redirects 0, max 0 per host",
+		"Admin CIDRs:   [10.0.0.0/8]",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("debug diagnostics missing %q:\n%s", line, out)
+		}
+	}
+}
+
+func TestPrintDiagnosticsInvalidCrons(t *testing.T) {
+	s := newDiagnosticsServer(t)
+	repo := repository.NewSubRepository(database.DB)
+
+	// Stored crons bypass the API validation, e.g. after a manual database edit
+	broken := &model.Sub{URL: "http://broken.example/sub", Cron: "0 */1 * * *", AutoUpdate: true}
+	manual := &model.Sub{URL: "http://manual.example/sub", Cron: "0 */1 * * *"}
+	for _, sub := range []*model.Sub{broken, manual} {
+		if err := repo.Create(context.Background(), sub); err != nil {
+			t.Fatalf("failed to create sub: %v", err)
+		}
+	}
+	if _, err := database.DB.Exec("UPDATE subs SET cron = ? WHERE id IN (?, ?)", "61 * * * *", broken.ID, manual.ID); err != nil {
+		t.Fatalf("failed to corrupt cron: %v", err)
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		s.config.Scheduler.ValidateOnStartup = true
+		logs := captureLogs(t, logger.LogLevelWarn)
+
+		s.printDiagnostics("127.0.0.1:8080")
+		out := logs.String()
+
+		if want := fmt.Sprintf("Subscription %d has an invalid cron \"61 * * * *\" and will not be scheduled", broken.ID); !strings.Contains(out, want) {
+			t.Errorf("diagnostics missing %q:\n%s", want, out)
+		}
+		if strings.Contains(out, fmt.Sprintf("Subscription %d ", manual.ID)) {
+			t.Errorf("sub without auto update reported:\n%s", out)
+		}
+		if !strings.Contains(out, "1 subscription(s) skipped because of invalid cron expressions") {
+			t.Errorf("diagnostics missing the skipped count:\n%s", out)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		s.config.Scheduler.ValidateOnStartup = false
+		logs := captureLogs(t, logger.LogLevelWarn)
+
+		s.printDiagnostics("127.0.0.1:8080")
+		if strings.Contains(logs.String(), "invalid cron") {
+			t.Errorf("crons checked while the startup check is disabled:\n%s", logs.String())
+		}
+	})
+}
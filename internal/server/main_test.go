@@ -0,0 +1,49 @@
+package server
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/logger"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+
+	dir, err := os.MkdirTemp("", "bestsub-server-test")
+	if err != nil {
+		panic(err)
+	}
+
+	dbConfig := database.DefaultConfig(filepath.Join(dir, "test.db"))
+	dbConfig.BcryptCost = bcrypt.MinCost
+	if err := database.InitDatabaseWithConfig(dbConfig); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+
+	database.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// captureLogs Collects log output at level until the test ends
+func captureLogs(t *testing.T, level logger.LogLevel) *bytes.Buffer {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	prevOutput := logger.SetOutput(buf)
+	prevLevel := logger.LogLevelSet
+	logger.LogLevelSet = level
+	t.Cleanup(func() {
+		logger.SetOutput(prevOutput)
+		logger.LogLevelSet = prevLevel
+	})
+	return buf
+}
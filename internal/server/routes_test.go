@@ -0,0 +1,109 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bestruirui/bestsub/internal/logger"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/router"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newRoutedServer Creates a server with all routes registered, configure adjusts the API-only config
+func newRoutedServer(t *testing.T, configure func(cfg *model.Config)) *Server {
+	t.Helper()
+	captureLogs(t, logger.LogLevelError)
+
+	cfg := &model.Config{}
+	cfg.Server.APIOnly = true
+	cfg.JWT.Secret = "0123456789abcdef0123456789abcdef"
+	cfg.JWT.ExpiresIn = 1
+	cfg.Password.BcryptCost = bcrypt.MinCost
+	cfg.Fetcher.MaxConcurrentPerHost = 2
+	cfg.Scheduler.DefaultCron = "0 */1 * * *"
+	if configure != nil {
+		configure(cfg)
+	}
+
+	s := NewServer(cfg)
+	s.setupRoutes()
+	return s
+}
+
+func TestRouteRedirects(t *testing.T) {
+	tests := []struct {
+		name          string
+		trailingSlash bool
+		caseFold      bool
+		path          string
+		wantStatus    int
+		wantLocation  string
+	}{
+		{"trailing slash", true, false, "/api/health/", http.StatusMovedPermanently, "/api/health"},
+		{"trailing slash disabled", false, false, "/api/health/", http.StatusNotFound, ""},
+		{"mixed case", false, true, "/API/Health", http.StatusMovedPermanently, "/api/health"},
+		{"mixed case disabled", false, false, "/API/Health", http.StatusNotFound, ""},
+		{"exact path", false, false, "/api/health", http.StatusOK, ""},
+		{"unknown path", true, true, "/no/such/page", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newRoutedServer(t, func(cfg *model.Config) {
+				cfg.Server.RedirectTrailingSlash = tt.trailingSlash
+				cfg.Server.CaseInsensitiveRoutes = tt.caseFold
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+			s.router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("GET %s status = %d, want %d: %s", tt.path, w.Code, tt.wantStatus, w.Body.String())
+			}
+			if got := w.Header().Get("Location"); got != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", got, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	s := newRoutedServer(t, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil)
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	var doc router.OpenAPIDocument
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to decode document: %v", err)
+	}
+	if doc.OpenAPI == "" || doc.Info.Title == "" {
+		t.Errorf("openapi = %q, title = %q, want both set", doc.OpenAPI, doc.Info.Title)
+	}
+
+	op := doc.Paths["/api/sub/{id}"]["get"]
+	if op == nil {
+		t.Fatalf("GET /api/sub/{id} missing from paths %v", doc.Paths)
+	}
+	if op.Summary != "Get subscription details" {
+		t.Errorf("summary = %q, want the route description", op.Summary)
+	}
+	if len(op.Tags) != 1 || op.Tags[0] != "/api/sub" {
+		t.Errorf("tags = %v, want [/api/sub]", op.Tags)
+	}
+	if len(op.Parameters) != 1 || op.Parameters[0].Name != "id" || op.Parameters[0].In != "path" || !op.Parameters[0].Required {
+		t.Errorf("parameters = %+v, want the required id path parameter", op.Parameters)
+	}
+
+	if doc.Paths["/api/health"]["get"] == nil {
+		t.Errorf("GET /api/health missing from paths")
+	}
+}
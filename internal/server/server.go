@@ -15,7 +15,9 @@ import (
 	"github.com/bestruirui/bestsub/internal/logger"
 	"github.com/bestruirui/bestsub/internal/middleware"
 	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/repository"
 	"github.com/bestruirui/bestsub/internal/router"
+	"github.com/bestruirui/bestsub/internal/service"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -26,6 +28,10 @@ type Server struct {
 	config     *model.Config
 	router     *gin.Engine
 	httpServer *http.Server
+	// challengeServer Serves ACME HTTP-01 challenges on port 80 when auto_cert is enabled
+	challengeServer *http.Server
+	// stopBackground Stops background jobs started by Start
+	stopBackground context.CancelFunc
 }
 
 // NewServer Creates and configures server instance
@@ -33,6 +39,10 @@ type Server struct {
 func NewServer(cfg *model.Config) *Server {
 	router := gin.New()
 
+	// Only routed paths are redirected, anything else still reaches the NoRoute handler
+	router.RedirectTrailingSlash = cfg.Server.RedirectTrailingSlash
+	router.RedirectFixedPath = cfg.Server.CaseInsensitiveRoutes
+
 	router.Use(gin.Recovery())
 
 	if gin.Mode() == gin.ReleaseMode {
@@ -43,6 +53,15 @@ func NewServer(cfg *model.Config) *Server {
 
 	router.Use(middleware.Cors())
 	router.Use(middleware.RequestLogger())
+	router.Use(middleware.ConnLimit(cfg.Server.MaxConcurrentPerIP))
+	if cfg.Server.LogBodies {
+		// Subscription content carries proxy credentials
+		router.Use(middleware.BodyLogger("/api/sub/:id/content"))
+	}
+	router.Use(middleware.I18n())
+
+	middleware.SetMaintenanceMode(cfg.Server.MaintenanceMode)
+	router.Use(middleware.Maintenance("/api/user/login", "/api/system/maintenance"))
 
 	return &Server{
 		config: cfg,
@@ -56,11 +75,20 @@ func NewServer(cfg *model.Config) *Server {
 // initDatabase Initializes database connection and schema
 func (s *Server) initDatabase() error {
 	logger.Info("Initializing database connection...")
-	err := database.InitDatabase(s.config.Database.Path)
+	dbConfig := database.DefaultConfig(s.config.Database.Path)
+	dbConfig.AutoMigrate = s.config.Database.AutoMigrate
+	dbConfig.BcryptCost = s.config.Password.BcryptCost
+	err := database.InitDatabaseWithConfig(dbConfig)
 	if err != nil {
 		return fmt.Errorf("database initialization failed: %v", err)
 	}
 	logger.Info("Database initialized successfully")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := service.InitTokenBlacklist(ctx, repository.NewTokenBlacklistRepository(database.DB)); err != nil {
+		return fmt.Errorf("failed to load token blacklist: %v", err)
+	}
 	return nil
 }
 
@@ -69,13 +97,20 @@ func (s *Server) setupRoutes() {
 	logger.Info("Setting up API routes...")
 
 	userHandler := handler.NewUserHandler(database.DB, s.config)
-	systemHandler := handler.NewSystemHandler(s.config)
+	systemHandler := handler.NewSystemHandler(database.DB, s.config)
 	subHandler := handler.NewSubHandler(database.DB, s.config)
 
 	router.MustRegisterGroup(s.router, userHandler)
 	router.MustRegisterGroup(s.router, systemHandler)
 	router.MustRegisterGroup(s.router, subHandler)
 
+	// Generated from the registered route groups, complements the annotated Swagger docs
+	openAPIDoc := router.NewOpenAPIDocument(docs.SwaggerInfo.Title, docs.SwaggerInfo.Version,
+		userHandler, systemHandler, subHandler)
+	s.router.GET("/api/openapi.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, openAPIDoc)
+	})
+
 	_ = docs.SwaggerInfo.ReadDoc()
 
 	s.router.GET("/api/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler,
@@ -91,6 +126,20 @@ func (s *Server) setupRoutes() {
 	logger.Info("Routes registered successfully")
 }
 
+// startBackgroundJobs Starts jobs that run for the lifetime of the server
+func (s *Server) startBackgroundJobs() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.stopBackground = cancel
+
+	maxAge := time.Duration(s.config.ContentStore.MaxAgeSeconds) * time.Second
+	service.StartContentSweeper(ctx, repository.NewSubRepository(database.DB), maxAge)
+	service.StartTokenBlacklistPruner(ctx)
+
+	retention := time.Duration(s.config.Database.AuditRetentionDays) * 24 * time.Hour
+	service.StartDatabaseMaintenance(ctx, database.DB, repository.NewAuditRepository(database.DB),
+		time.Duration(s.config.Database.MaintenanceIntervalHours)*time.Hour, retention)
+}
+
 // Start Starts HTTP server and handles graceful shutdown
 func (s *Server) Start() error {
 	if err := s.initDatabase(); err != nil {
@@ -98,19 +147,34 @@ func (s *Server) Start() error {
 	}
 
 	s.setupRoutes()
+	s.startBackgroundJobs()
 
 	serverAddr := fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port)
 	s.httpServer.Addr = serverAddr
 
 	s.httpServer.ReadTimeout = 10 * time.Second
-	s.httpServer.WriteTimeout = 30 * time.Second
+	// Leave room for handlers granted a longer timeout through X-Timeout-Seconds
+	s.httpServer.WriteTimeout = max(30*time.Second, time.Duration(s.config.Server.MaxRequestTimeoutSeconds+5)*time.Second)
 	s.httpServer.IdleTimeout = 120 * time.Second
 
+	s.printDiagnostics(serverAddr)
+
 	go s.gracefulShutdown()
 
-	logger.Info("Server started, listening on: %s", serverAddr)
+	if err := s.setupTLS(); err != nil {
+		return err
+	}
+
+	var err error
+	if s.httpServer.TLSConfig != nil {
+		logger.Info("Server started, listening on: %s (HTTPS)", serverAddr)
+		err = s.httpServer.ListenAndServeTLS("", "")
+	} else {
+		logger.Info("Server started, listening on: %s", serverAddr)
+		err = s.httpServer.ListenAndServe()
+	}
 
-	if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("failed to start server: %v", err)
 	}
 
@@ -125,6 +189,10 @@ func (s *Server) gracefulShutdown() {
 
 	logger.Info("Shutting down server...")
 
+	if s.stopBackground != nil {
+		s.stopBackground()
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -132,6 +200,12 @@ func (s *Server) gracefulShutdown() {
 		logger.Error("Server forced to shutdown: %v", err)
 	}
 
+	if s.challengeServer != nil {
+		if err := s.challengeServer.Shutdown(ctx); err != nil {
+			logger.Error("ACME challenge server forced to shutdown: %v", err)
+		}
+	}
+
 	if err := database.Close(); err != nil {
 		logger.Error("Error closing database connection: %v", err)
 	}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/logger"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// DefaultAutoCertCacheDir Directory issued certificates are cached in when auto_cert.cache_dir is empty
+const DefaultAutoCertCacheDir = "data/autocert"
+
+// certReloader Serves a certificate pair from disk and reloads it when either file changes
+// so renewed certificates are picked up without a restart
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	certTime time.Time
+	keyTime  time.Time
+}
+
+// newCertReloader Loads the certificate pair, failing when it cannot be read
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile}
+	certTime, keyTime, err := r.modTimes()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(certTime, keyTime); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// modTimes Returns the modification times of the certificate and key files
+func (r *certReloader) modTimes() (time.Time, time.Time, error) {
+	certInfo, err := os.Stat(r.certFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to stat TLS certificate: %w", err)
+	}
+	keyInfo, err := os.Stat(r.keyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to stat TLS key: %w", err)
+	}
+	return certInfo.ModTime(), keyInfo.ModTime(), nil
+}
+
+// load Reads the certificate pair and records the file times it was read at
+func (r *certReloader) load(certTime, keyTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.certTime = certTime
+	r.keyTime = keyTime
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate tls.Config callback, reloads the pair first if the files changed
+// A failed reload keeps serving the previous certificate
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certTime, keyTime, err := r.modTimes()
+
+	r.mu.RLock()
+	changed := err == nil && (!certTime.Equal(r.certTime) || !keyTime.Equal(r.keyTime))
+	r.mu.RUnlock()
+
+	if changed {
+		if err := r.load(certTime, keyTime); err != nil {
+			logger.Warn("TLS certificate reload failed, keeping previous certificate: %v", err)
+		} else {
+			logger.Info("TLS certificate reloaded from %s", r.certFile)
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// setupTLS Configures HTTPS from a certificate pair or from autocert, leaves TLSConfig nil for plain HTTP
+func (s *Server) setupTLS() error {
+	tlsCfg := s.config.Server.TLS
+
+	if tlsCfg.CertFile != "" {
+		reloader, err := newCertReloader(tlsCfg.CertFile, tlsCfg.KeyFile)
+		if err != nil {
+			return err
+		}
+		s.httpServer.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+		}
+		return nil
+	}
+
+	if len(tlsCfg.AutoCert.Domains) == 0 {
+		return nil
+	}
+
+	manager := newAutoCertManager(tlsCfg.AutoCert.Domains, tlsCfg.AutoCert.CacheDir, tlsCfg.AutoCert.Email)
+	s.httpServer.TLSConfig = manager.TLSConfig()
+
+	// Port 80 answers HTTP-01 challenges and redirects everything else to HTTPS
+	s.challengeServer = &http.Server{
+		Addr:              fmt.Sprintf("%s:80", s.config.Server.Host),
+		Handler:           manager.HTTPHandler(nil),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := s.challengeServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("ACME challenge server failed: %v", err)
+		}
+	}()
+
+	logger.Info("Automatic certificates enabled for %v", tlsCfg.AutoCert.Domains)
+	return nil
+}
+
+// newAutoCertManager Creates an autocert manager that only issues certificates for domains
+func newAutoCertManager(domains []string, cacheDir, email string) *autocert.Manager {
+	if cacheDir == "" {
+		cacheDir = DefaultAutoCertCacheDir
+	}
+
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(domains...),
+		Cache:      autocert.DirCache(cacheDir),
+		Email:      email,
+	}
+}
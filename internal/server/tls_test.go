@@ -0,0 +1,212 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/model"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// writeSelfSignedCert Writes a self-signed certificate for 127.0.0.1 with serial to dir
+func writeSelfSignedCert(t *testing.T, dir string, serial int64) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "bestsub test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err = x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile, cert
+}
+
+func TestServeHTTPS(t *testing.T) {
+	certFile, keyFile, cert := writeSelfSignedCert(t, t.TempDir(), 1)
+
+	cfg := &model.Config{}
+	cfg.Server.APIOnly = true
+	cfg.Server.TLS.CertFile = certFile
+	cfg.Server.TLS.KeyFile = keyFile
+	cfg.JWT.Secret = "0123456789abcdef0123456789abcdef"
+
+	s := NewServer(cfg)
+	s.setupRoutes()
+	if err := s.setupTLS(); err != nil {
+		t.Fatalf("setupTLS error = %v", err)
+	}
+	if s.httpServer.TLSConfig == nil {
+		t.Fatal("TLSConfig is nil with a certificate configured")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.httpServer.ServeTLS(listener, "", "")
+	t.Cleanup(func() { s.httpServer.Close() })
+
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+
+	resp, err := client.Get("https://" + listener.Addr().String() + "/api/health")
+	if err != nil {
+		t.Fatalf("HTTPS request error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), `"status":"ok"`) {
+		t.Errorf("response = %d %s, want 200 with status ok", resp.StatusCode, body)
+	}
+	if resp.TLS == nil || resp.TLS.PeerCertificates[0].SerialNumber.Int64() != 1 {
+		t.Error("response was not served with the configured certificate")
+	}
+}
+
+func TestServePlainHTTPWithoutTLS(t *testing.T) {
+	s := NewServer(&model.Config{})
+	if err := s.setupTLS(); err != nil {
+		t.Fatalf("setupTLS error = %v", err)
+	}
+	if s.httpServer.TLSConfig != nil {
+		t.Error("TLSConfig is set without a certificate or auto_cert domains")
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, _ := writeSelfSignedCert(t, dir, 1)
+
+	reloader, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("newCertReloader error = %v", err)
+	}
+
+	serial := func() int64 {
+		t.Helper()
+		cert, err := reloader.GetCertificate(nil)
+		if err != nil {
+			t.Fatalf("GetCertificate error = %v", err)
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.SerialNumber.Int64()
+	}
+	if got := serial(); got != 1 {
+		t.Fatalf("serial = %d, want 1", got)
+	}
+
+	// A renewal replaces both files, bump the times so the change is seen at coarse timestamp resolution
+	writeSelfSignedCert(t, dir, 2)
+	later := time.Now().Add(time.Minute)
+	for _, f := range []string{certFile, keyFile} {
+		if err := os.Chtimes(f, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := serial(); got != 2 {
+		t.Errorf("serial after renewal = %d, want 2", got)
+	}
+
+	// A broken renewal keeps the previous certificate
+	if err := os.WriteFile(certFile, []byte("broken"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	evenLater := later.Add(time.Minute)
+	os.Chtimes(certFile, evenLater, evenLater)
+	if got := serial(); got != 2 {
+		t.Errorf("serial after broken renewal = %d, want 2", got)
+	}
+}
+
+func TestNewCertReloaderMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := newCertReloader(filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")); err == nil {
+		t.Error("newCertReloader error = nil, want an error for missing files")
+	}
+}
+
+func TestAutoCertHostPolicy(t *testing.T) {
+	cacheDir := t.TempDir()
+	manager := newAutoCertManager([]string{"sub.example.com", "api.example.com"}, cacheDir, "ops@example.com")
+
+	tests := []struct {
+		host    string
+		allowed bool
+	}{
+		{"sub.example.com", true},
+		{"api.example.com", true},
+		{"other.example.com", false},
+		{"example.com", false},
+	}
+	for _, tt := range tests {
+		err := manager.HostPolicy(context.Background(), tt.host)
+		if tt.allowed && err != nil {
+			t.Errorf("HostPolicy(%q) error = %v, want allowed", tt.host, err)
+		}
+		if !tt.allowed && err == nil {
+			t.Errorf("HostPolicy(%q) allowed, want rejected", tt.host)
+		}
+	}
+
+	if manager.Email != "ops@example.com" {
+		t.Errorf("email = %q, want ops@example.com", manager.Email)
+	}
+	if dir, ok := manager.Cache.(autocert.DirCache); !ok || string(dir) != cacheDir {
+		t.Errorf("cache = %v, want DirCache(%q)", manager.Cache, cacheDir)
+	}
+}
+
+func TestAutoCertDefaultCacheDir(t *testing.T) {
+	manager := newAutoCertManager([]string{"sub.example.com"}, "", "")
+	if dir, ok := manager.Cache.(autocert.DirCache); !ok || string(dir) != DefaultAutoCertCacheDir {
+		t.Errorf("cache = %v, want DirCache(%q)", manager.Cache, DefaultAutoCertCacheDir)
+	}
+}
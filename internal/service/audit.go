@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/logger"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/repository"
+)
+
+// auditWriteTimeout Upper bound for a single audit write
+const auditWriteTimeout = 5 * time.Second
+
+// AuditService Records user actions for security review
+type AuditService struct {
+	auditRepo repository.AuditRepository
+}
+
+// NewAuditService Create a new audit service instance
+func NewAuditService(auditRepo repository.AuditRepository) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// Record Writes an audit entry
+// Failures are only logged so auditing never fails the audited operation,
+// and the write is detached from the request context so a finished request cannot cancel it
+func (s *AuditService) Record(userID int64, action, target string) {
+	ctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+	defer cancel()
+
+	entry := &model.AuditLog{
+		UserID: userID,
+		Action: action,
+		Target: target,
+	}
+
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		logger.Error("Failed to write audit log: %v, Action: %s, Target: %s", err, action, target)
+	}
+}
+
+// List Returns a page of audit entries and the total count
+func (s *AuditService) List(ctx context.Context, limit, offset int) ([]*model.AuditLog, int, error) {
+	return s.auditRepo.List(ctx, limit, offset)
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/repository"
+)
+
+var (
+	ErrSnapshotNotFound    = errors.New("content snapshot not found")
+	ErrInvalidSnapshotName = errors.New("invalid content snapshot name")
+)
+
+// snapshotTimeFormat Timestamp layout used in snapshot file names, fixed width so names sort chronologically
+const snapshotTimeFormat = "20060102-150405.000000"
+
+// maxSnapshotNameAttempts Bound on retries when a snapshot name is already taken
+const maxSnapshotNameAttempts = 100
+
+// snapshotPrefix File name prefix of snapshots belonging to a sub
+func snapshotPrefix(subID int64) string {
+	return fmt.Sprintf("sub-%d-", subID)
+}
+
+// SaveContentSnapshot Writes the stored content of a sub to a timestamped file in dir
+// Returns the snapshot file name
+func SaveContentSnapshot(dir string, subID int64) (string, error) {
+	content, err := GetSubContent(subID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	// Names are only claimed if the file does not exist yet, a snapshot taken in the same microsecond
+	// retries with a later timestamp instead of overwriting the earlier one
+	for range maxSnapshotNameAttempts {
+		name := snapshotPrefix(subID) + time.Now().Format(snapshotTimeFormat) + ".txt"
+		err := writeNewFile(filepath.Join(dir, name), []byte(content))
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		return name, nil
+	}
+
+	return "", fmt.Errorf("failed to write snapshot: no free snapshot name after %d attempts", maxSnapshotNameAttempts)
+}
+
+// writeNewFile Writes data to path, failing with os.ErrExist if the file already exists
+func writeNewFile(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// LoadContentSnapshot Restores a snapshot of a sub into the content store
+// An empty name loads the most recent snapshot of the sub, returns the loaded snapshot name.
+// The sub's last fetch time and content size are updated as for a fetch, so the content sweeper keeps the restored content
+func LoadContentSnapshot(ctx context.Context, subRepo repository.SubRepository, dir string, subID int64, name string) (string, error) {
+	prefix := snapshotPrefix(subID)
+
+	if name == "" {
+		matches, err := filepath.Glob(filepath.Join(dir, prefix+"*.txt"))
+		if err != nil {
+			return "", fmt.Errorf("failed to list snapshots: %w", err)
+		}
+		if len(matches) == 0 {
+			return "", ErrSnapshotNotFound
+		}
+		sort.Strings(matches)
+		name = filepath.Base(matches[len(matches)-1])
+	}
+
+	// Only plain file names of this sub's snapshots are accepted
+	if name != filepath.Base(name) || !strings.HasPrefix(name, prefix) {
+		return "", ErrInvalidSnapshotName
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", ErrSnapshotNotFound
+		}
+		return "", fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	if err := StoreSubContent(subID, string(data)); err != nil {
+		return "", err
+	}
+
+	if err := subRepo.UpdateLastFetch(ctx, subID, int64(len(data))); err != nil {
+		DeleteSubContent(subID)
+		return "", err
+	}
+
+	return name, nil
+}
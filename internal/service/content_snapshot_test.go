@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/repository"
+)
+
+func TestContentSnapshotRoundTrip(t *testing.T) {
+	resetSubs(t)
+	repo := repository.NewSubRepository(database.DB)
+	sub := createTestSub(t, repo, &model.Sub{URL: "http://a.example/sub"})
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	const content = "vmess://node-1\nvmess://node-2\n"
+	if err := StoreSubContent(sub.ID, content); err != nil {
+		t.Fatal(err)
+	}
+	name, err := SaveContentSnapshot(dir, sub.ID)
+	if err != nil {
+		t.Fatalf("SaveContentSnapshot error = %v", err)
+	}
+
+	// Restoring after a restart, with last_fetch long past the sweeper's max age
+	ClearAllContent()
+	setLastFetch(t, sub.ID, time.Now().Add(-48*time.Hour))
+
+	loaded, err := LoadContentSnapshot(ctx, repo, dir, sub.ID, "")
+	if err != nil {
+		t.Fatalf("LoadContentSnapshot error = %v", err)
+	}
+	if loaded != name {
+		t.Errorf("loaded snapshot = %q, want the latest %q", loaded, name)
+	}
+	if got, _ := GetSubContent(sub.ID); got != content {
+		t.Errorf("restored content = %q, want %q", got, content)
+	}
+
+	stored, err := repo.GetByID(ctx, sub.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.ContentSize != int64(len(content)) {
+		t.Errorf("content size = %d, want %d", stored.ContentSize, len(content))
+	}
+
+	if _, err := SweepStaleContent(ctx, repo, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if !hasContent(sub.ID) {
+		t.Error("restored content was evicted by the sweeper")
+	}
+
+	// Loading by name works as well
+	ClearAllContent()
+	if _, err := LoadContentSnapshot(ctx, repo, dir, sub.ID, name); err != nil {
+		t.Fatalf("LoadContentSnapshot(%q) error = %v", name, err)
+	}
+	if got, _ := GetSubContent(sub.ID); got != content {
+		t.Errorf("content loaded by name = %q, want %q", got, content)
+	}
+}
+
+func TestLoadContentSnapshotErrors(t *testing.T) {
+	resetSubs(t)
+	repo := repository.NewSubRepository(database.DB)
+	sub := createTestSub(t, repo, &model.Sub{URL: "http://a.example/sub"})
+	other := createTestSub(t, repo, &model.Sub{URL: "http://b.example/sub"})
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	storeContent(t, other.ID)
+	otherName, err := SaveContentSnapshot(dir, other.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		subID    int64
+		snapshot string
+		want     error
+	}{
+		{"no snapshot", sub.ID, "", ErrSnapshotNotFound},
+		{"missing file", sub.ID, snapshotPrefix(sub.ID) + "20240101-000000.000000.txt", ErrSnapshotNotFound},
+		{"other sub", sub.ID, otherName, ErrInvalidSnapshotName},
+		{"path traversal", sub.ID, "../" + snapshotPrefix(sub.ID) + "x.txt", ErrInvalidSnapshotName},
+	}
+	for _, tt := range tests {
+		if _, err := LoadContentSnapshot(ctx, repo, dir, tt.subID, tt.snapshot); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// A snapshot of a deleted sub is not restored
+	if err := repo.Delete(ctx, other.ID); err != nil {
+		t.Fatal(err)
+	}
+	DeleteSubContent(other.ID)
+	if _, err := LoadContentSnapshot(ctx, repo, dir, other.ID, otherName); !errors.Is(err, model.ErrSubNotFound) {
+		t.Errorf("deleted sub: error = %v, want ErrSubNotFound", err)
+	}
+	if hasContent(other.ID) {
+		t.Error("content of a deleted sub was restored")
+	}
+}
+
+func TestContentSnapshotsInQuickSuccession(t *testing.T) {
+	resetSubs(t)
+	repo := repository.NewSubRepository(database.DB)
+	sub := createTestSub(t, repo, &model.Sub{URL: "http://a.example/sub"})
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	contents := []string{"vmess://first", "vmess://second", "vmess://third"}
+	names := make([]string, len(contents))
+	for i, content := range contents {
+		if err := StoreSubContent(sub.ID, content); err != nil {
+			t.Fatal(err)
+		}
+		name, err := SaveContentSnapshot(dir, sub.ID)
+		if err != nil {
+			t.Fatalf("SaveContentSnapshot error = %v", err)
+		}
+		names[i] = name
+	}
+
+	for i, name := range names {
+		if _, err := LoadContentSnapshot(ctx, repo, dir, sub.ID, name); err != nil {
+			t.Fatalf("LoadContentSnapshot(%q) error = %v", name, err)
+		}
+		if got, _ := GetSubContent(sub.ID); got != contents[i] {
+			t.Errorf("snapshot %q = %q, want %q", name, got, contents[i])
+		}
+	}
+
+	latest, err := LoadContentSnapshot(ctx, repo, dir, sub.ID, "")
+	if err != nil {
+		t.Fatalf("LoadContentSnapshot error = %v", err)
+	}
+	if latest != names[len(names)-1] {
+		t.Errorf("latest snapshot = %q, want %q", latest, names[len(names)-1])
+	}
+}
@@ -41,6 +41,18 @@ func DeleteSubContent(subID int64) {
 	delete(subContentStore, subID)
 }
 
+// cachedSubIDs Returns the IDs of all subs with cached content
+func cachedSubIDs() []int64 {
+	subContentStoreMutex.RLock()
+	defer subContentStoreMutex.RUnlock()
+
+	ids := make([]int64, 0, len(subContentStore))
+	for id := range subContentStore {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
 func ClearAllContent() {
 	subContentStoreMutex.Lock()
 	defer subContentStoreMutex.Unlock()
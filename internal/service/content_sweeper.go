@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/logger"
+	"github.com/bestruirui/bestsub/internal/repository"
+)
+
+// maxContentSweepInterval Upper bound on the time between two content sweeps
+const maxContentSweepInterval = 10 * time.Minute
+
+// StartContentSweeper Periodically evicts cached content of unpinned subs whose last fetch is older than maxAge
+// and of subs that no longer exist, until ctx is cancelled
+func StartContentSweeper(ctx context.Context, subRepo repository.SubRepository, maxAge time.Duration) {
+	if maxAge <= 0 {
+		return
+	}
+
+	interval := min(maxAge, maxContentSweepInterval)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if n, err := SweepStaleContent(ctx, subRepo, now.Add(-maxAge)); err != nil {
+					logger.Error("Content sweep failed: %v", err)
+				} else if n > 0 {
+					logger.Debug("Content sweep evicted %d entries", n)
+				}
+			}
+		}
+	}()
+}
+
+// SweepStaleContent Evicts cached content of subs last fetched before cutoff and of deleted subs
+// Content of pinned subs is kept whatever its age. Returns the number of evicted entries
+func SweepStaleContent(ctx context.Context, subRepo repository.SubRepository, cutoff time.Time) (int, error) {
+	subs, err := subRepo.GetAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	fresh := make(map[int64]bool, len(subs))
+	for _, sub := range subs {
+		if sub.Pinned || (sub.LastFetch != nil && !sub.LastFetch.Before(cutoff)) {
+			fresh[sub.ID] = true
+		}
+	}
+
+	evicted := 0
+	for _, id := range cachedSubIDs() {
+		if !fresh[id] {
+			DeleteSubContent(id)
+			evicted++
+		}
+	}
+	return evicted, nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/repository"
+)
+
+// setLastFetch Overwrites the last fetch time of a sub
+func setLastFetch(t *testing.T, id int64, at time.Time) {
+	t.Helper()
+
+	if _, err := database.DB.Exec("UPDATE subs SET last_fetch = ? WHERE id = ?", at.Local().Format(time.RFC3339), id); err != nil {
+		t.Fatalf("failed to set last fetch: %v", err)
+	}
+}
+
+// storeContent Stores content for each of ids
+func storeContent(t *testing.T, ids ...int64) {
+	t.Helper()
+
+	for _, id := range ids {
+		if err := StoreSubContent(id, "content"); err != nil {
+			t.Fatalf("StoreSubContent error = %v", err)
+		}
+	}
+}
+
+// hasContent Reports whether content is cached for id
+func hasContent(id int64) bool {
+	_, err := GetSubContent(id)
+	return err == nil
+}
+
+func TestSweepStaleContent(t *testing.T) {
+	resetSubs(t)
+	repo := repository.NewSubRepository(database.DB)
+
+	fresh := createTestSub(t, repo, &model.Sub{URL: "http://fresh.example/sub"})
+	stale := createTestSub(t, repo, &model.Sub{URL: "http://stale.example/sub"})
+	never := createTestSub(t, repo, &model.Sub{URL: "http://never.example/sub"})
+	const deleted = int64(1 << 40)
+
+	now := time.Now()
+	setLastFetch(t, fresh.ID, now.Add(-10*time.Minute))
+	setLastFetch(t, stale.ID, now.Add(-2*time.Hour))
+	storeContent(t, fresh.ID, stale.ID, never.ID, deleted)
+
+	n, err := SweepStaleContent(context.Background(), repo, now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("SweepStaleContent error = %v", err)
+	}
+	if n != 3 {
+		t.Errorf("evicted = %d, want 3", n)
+	}
+
+	if !hasContent(fresh.ID) {
+		t.Error("content fetched within max age was evicted")
+	}
+	for name, id := range map[string]int64{"stale": stale.ID, "never fetched": never.ID, "deleted": deleted} {
+		if hasContent(id) {
+			t.Errorf("content of %s sub was kept", name)
+		}
+	}
+}
+
+func TestSweepStaleContentKeepsPinned(t *testing.T) {
+	resetSubs(t)
+	repo := repository.NewSubRepository(database.DB)
+
+	pinned := createTestSub(t, repo, &model.Sub{URL: "http://pinned.example/sub"})
+	if err := repo.SetPinned(context.Background(), pinned.ID, true, ""); err != nil {
+		t.Fatalf("SetPinned error = %v", err)
+	}
+	setLastFetch(t, pinned.ID, time.Now().Add(-48*time.Hour))
+	storeContent(t, pinned.ID)
+
+	n, err := SweepStaleContent(context.Background(), repo, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("SweepStaleContent error = %v", err)
+	}
+	if n != 0 || !hasContent(pinned.ID) {
+		t.Errorf("evicted = %d, content kept = %v, want pinned content kept", n, hasContent(pinned.ID))
+	}
+}
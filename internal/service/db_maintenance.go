@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/logger"
+	"github.com/bestruirui/bestsub/internal/repository"
+)
+
+// StartDatabaseMaintenance Periodically prunes audit log entries older than retention and vacuums
+// the database, until ctx is cancelled. retention <= 0 keeps the audit log forever
+func StartDatabaseMaintenance(ctx context.Context, db *sql.DB, auditRepo repository.AuditRepository, interval, retention time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				var cutoff time.Time
+				if retention > 0 {
+					cutoff = now.Add(-retention)
+				}
+				if err := RunDatabaseMaintenance(ctx, db, auditRepo, cutoff); err != nil {
+					logger.Error("Database maintenance failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// RunDatabaseMaintenance Deletes audit log entries created before cutoff, then vacuums the database
+// A zero cutoff skips the audit log pruning
+func RunDatabaseMaintenance(ctx context.Context, db *sql.DB, auditRepo repository.AuditRepository, cutoff time.Time) error {
+	if !cutoff.IsZero() {
+		n, err := auditRepo.DeleteBefore(ctx, cutoff)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			logger.Debug("Database maintenance removed %d audit log entries", n)
+		}
+	}
+
+	start := time.Now()
+	if err := database.Vacuum(ctx, db); err != nil {
+		return err
+	}
+	logger.Debug("Database vacuum finished in %s", time.Since(start))
+	return nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/repository"
+)
+
+// freePages Returns the number of unused pages in the test database
+func freePages(t *testing.T) int {
+	t.Helper()
+
+	var n int
+	if err := database.DB.QueryRow("PRAGMA freelist_count").Scan(&n); err != nil {
+		t.Fatalf("failed to read freelist count: %v", err)
+	}
+	return n
+}
+
+func TestRunDatabaseMaintenance(t *testing.T) {
+	ctx := context.Background()
+	resetSubs(t)
+	if _, err := database.DB.Exec("DELETE FROM audit_log"); err != nil {
+		t.Fatalf("failed to empty audit log: %v", err)
+	}
+	subRepo := repository.NewSubRepository(database.DB)
+	auditRepo := repository.NewAuditRepository(database.DB)
+
+	// Deleted rows leave free pages behind for the vacuum to release
+	padding := strings.Repeat("x", 2048)
+	for i := range 200 {
+		createTestSub(t, subRepo, &model.Sub{URL: fmt.Sprintf("http://%d.example/sub", i), Remark: padding[:256]})
+		if err := auditRepo.Create(ctx, &model.AuditLog{UserID: model.AdminUserID, Action: model.AuditSubDelete, Target: padding}); err != nil {
+			t.Fatalf("failed to create audit log: %v", err)
+		}
+	}
+	resetSubs(t)
+	if freePages(t) == 0 {
+		t.Fatal("no free pages after deleting the subs")
+	}
+
+	if _, err := database.DB.Exec("UPDATE audit_log SET created_at = ? WHERE id % 2 = 0",
+		time.Now().Add(-48*time.Hour).Local().Format(time.RFC3339)); err != nil {
+		t.Fatalf("failed to age audit log: %v", err)
+	}
+
+	if err := RunDatabaseMaintenance(ctx, database.DB, auditRepo, time.Now().Add(-24*time.Hour)); err != nil {
+		t.Fatalf("RunDatabaseMaintenance error = %v", err)
+	}
+
+	_, total, err := auditRepo.List(ctx, 1, 0)
+	if err != nil {
+		t.Fatalf("List error = %v", err)
+	}
+	if total != 100 {
+		t.Errorf("audit log entries = %d, want the 100 recent ones kept", total)
+	}
+	if n := freePages(t); n != 0 {
+		t.Errorf("free pages after maintenance = %d, want 0", n)
+	}
+
+	// A zero cutoff only vacuums
+	if err := RunDatabaseMaintenance(ctx, database.DB, auditRepo, time.Time{}); err != nil {
+		t.Fatalf("RunDatabaseMaintenance without cutoff error = %v", err)
+	}
+	if _, total, _ := auditRepo.List(ctx, 1, 0); total != 100 {
+		t.Errorf("audit log entries = %d after vacuum only, want 100", total)
+	}
+}
@@ -2,10 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"text/template"
 	"time"
 
 	"github.com/bestruirui/bestsub/internal/logger"
@@ -13,22 +19,83 @@ import (
 	"github.com/bestruirui/bestsub/internal/repository"
 )
 
+const (
+	// DefaultFetchTimeout Fetch timeout used when neither the config nor the sub sets one
+	DefaultFetchTimeout = 30 * time.Second
+	// DefaultDialTimeout Connection timeout used when the config does not set one
+	DefaultDialTimeout = 10 * time.Second
+	// DefaultTLSHandshakeTimeout TLS handshake timeout used when the config does not set one
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+)
+
+// subQueryTimeout Upper bound for each database call around a fetch, callers may pass a context without deadline
+const subQueryTimeout = 10 * time.Second
+
+// ErrRedirectsDisabled Returned for redirects while fetcher.max_redirects is 0
+var ErrRedirectsDisabled = errors.New("redirects are disabled")
+
+// activeFetches Number of subscription fetches currently in flight
+var activeFetches atomic.Int64
+
+// ActiveFetches Returns the number of subscription fetches currently in flight
+func ActiveFetches() int64 {
+	return activeFetches.Load()
+}
+
 // SubFetcher Subscription content retrieval service
 type SubFetcher struct {
-	subRepo    repository.SubRepository
-	httpClient *http.Client
+	subRepo     repository.SubRepository
+	httpClient  *http.Client
+	hostLimiter *hostLimiter
+	timeout     time.Duration
+	// keepOnEmpty Rejects empty content instead of overwriting the stored content
+	keepOnEmpty bool
 }
 
 // NewSubFetcher Create a new subscription retrieval service
-func NewSubFetcher(subRepo repository.SubRepository) *SubFetcher {
+func NewSubFetcher(subRepo repository.SubRepository, config *model.Config) *SubFetcher {
+	maxRedirects := config.Fetcher.MaxRedirects
+
+	timeout := time.Duration(config.Fetcher.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = DefaultFetchTimeout
+	}
+
+	dialTimeout := time.Duration(config.Fetcher.DialTimeoutSeconds) * time.Second
+	if dialTimeout <= 0 {
+		dialTimeout = DefaultDialTimeout
+	}
+
+	tlsHandshakeTimeout := time.Duration(config.Fetcher.TLSHandshakeTimeoutSeconds) * time.Second
+	if tlsHandshakeTimeout <= 0 {
+		tlsHandshakeTimeout = DefaultTLSHandshakeTimeout
+	}
+
+	// Connection setup gets its own short limits so unreachable hosts fail fast,
+	// while slow but steady bodies are only bounded by the overall timeout
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   dialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = tlsHandshakeTimeout
+
+	// The overall timeout is applied per request in fetchContent, so subs can override it
 	return &SubFetcher{
-		subRepo: subRepo,
+		subRepo:     subRepo,
+		hostLimiter: newHostLimiter(config.Fetcher.MaxConcurrentPerHost),
+		timeout:     timeout,
+		keepOnEmpty: config.Fetcher.KeepPreviousOnEmpty,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Transport: transport,
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				if len(via) >= 10 {
-					return fmt.Errorf("too many redirects")
+				if maxRedirects <= 0 {
+					return ErrRedirectsDisabled
+				}
+				if len(via) >= maxRedirects {
+					return fmt.Errorf("stopped after %d redirects", maxRedirects)
 				}
+				logger.Debug("Subscription fetch redirected: %s -> %s", logSafeURL(via[len(via)-1].URL), logSafeURL(req.URL))
 				return nil
 			},
 		},
@@ -37,30 +104,83 @@ func NewSubFetcher(subRepo repository.SubRepository) *SubFetcher {
 
 // FetchSub Fetch subscription content
 func (f *SubFetcher) FetchSub(ctx context.Context, subID int64) (*model.Sub, error) {
+	return f.FetchSubWithTimeout(ctx, subID, 0)
+}
+
+// FetchSubWithTimeout Fetch subscription content, a positive timeout overrides the per-sub and global timeouts
+func (f *SubFetcher) FetchSubWithTimeout(ctx context.Context, subID int64, override time.Duration) (*model.Sub, error) {
 	// Get subscription information
-	sub, err := f.subRepo.GetByID(ctx, subID)
+	queryCtx, cancel := context.WithTimeout(ctx, subQueryTimeout)
+	sub, err := f.subRepo.GetByID(queryCtx, subID)
+	cancel()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get subscription: %w", err)
 	}
 
+	// Resolve templated URL
+	subURL, err := RenderSubURL(sub.URL, sub.URLVars)
+	if err != nil {
+		return nil, err
+	}
+
+	// Per-sub timeout overrides the global one
+	timeout := f.timeout
+	if sub.FetchTimeoutSeconds > 0 {
+		timeout = time.Duration(sub.FetchTimeoutSeconds) * time.Second
+	}
+	if override > 0 {
+		timeout = override
+	}
+
 	// Get subscription content
-	content, err := f.fetchContent(ctx, sub.URL)
+	content, err := f.fetchContent(ctx, subURL, sub.FetchMethod, sub.FetchBody, timeout)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch content: %w", err)
 	}
 
-	// Store content to global memory cache
-	if err := StoreSubContent(subID, content); err != nil {
+	// An empty body usually means a provider hiccup, keep the last good content
+	if f.keepOnEmpty && strings.TrimSpace(content) == "" {
+		logger.Warn("Subscription %d returned empty content, keeping previous content", subID)
+		return nil, model.ErrEmptyContent
+	}
+
+	// Providers may answer 200 with an error or login page, reject content not matching the sub's pattern
+	if sub.SuccessPattern != "" {
+		pattern, err := regexp.Compile(sub.SuccessPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid success pattern: %w", err)
+		}
+		if !pattern.MatchString(content) {
+			logger.Warn("Subscription %d content does not match success pattern, keeping previous content", subID)
+			return nil, model.ErrContentReject
+		}
+	}
+
+	// Pinned subs record the fetch but keep serving the content that was pinned,
+	// the fetched content is never adopted
+	contentSize := int64(len(content))
+	if sub.Pinned {
+		logger.Debug("Subscription %d is pinned, keeping pinned content", subID)
+		contentSize = sub.ContentSize
+		if _, err := GetSubContent(subID); errors.Is(err, ErrContentNotFound) {
+			if err := f.restorePinnedContent(ctx, subID); err != nil {
+				return nil, err
+			}
+		}
+	} else if err := StoreSubContent(subID, content); err != nil {
 		return nil, fmt.Errorf("failed to store content: %w", err)
 	}
 
-	// Update last fetch time
-	if err := f.subRepo.UpdateLastFetch(ctx, subID); err != nil {
+	queryCtx, cancel = context.WithTimeout(ctx, subQueryTimeout)
+	defer cancel()
+
+	// Update last fetch time and content size
+	if err := f.subRepo.UpdateLastFetch(queryCtx, subID, contentSize); err != nil {
 		logger.Error("Failed to update last fetch time: %v", err)
 	}
 
 	// Get updated subscription information
-	updatedSub, err := f.subRepo.GetByID(ctx, subID)
+	updatedSub, err := f.subRepo.GetByID(queryCtx, subID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get updated subscription: %w", err)
 	}
@@ -68,39 +188,146 @@ func (f *SubFetcher) FetchSub(ctx context.Context, subID int64) (*model.Sub, err
 	return updatedSub, nil
 }
 
+// restorePinnedContent Reloads the persisted pinned copy of a sub into the content store
+// Without a persisted copy the store is left empty rather than adopting fetched content
+func (f *SubFetcher) restorePinnedContent(ctx context.Context, subID int64) error {
+	queryCtx, cancel := context.WithTimeout(ctx, subQueryTimeout)
+	defer cancel()
+
+	pinned, ok, err := f.subRepo.GetPinnedContent(queryCtx, subID)
+	if err != nil {
+		return fmt.Errorf("failed to get pinned content: %w", err)
+	}
+	if !ok {
+		logger.Warn("Subscription %d is pinned but has no pinned copy, leaving content empty", subID)
+		return nil
+	}
+
+	if err := StoreSubContent(subID, pinned); err != nil {
+		return fmt.Errorf("failed to restore pinned content: %w", err)
+	}
+	logger.Info("Restored pinned content for subscription %d", subID)
+	return nil
+}
+
+// FetchURL Fetches an arbitrary URL with the global fetch timeout and host limits
+func (f *SubFetcher) FetchURL(ctx context.Context, rawURL string) (string, error) {
+	return f.fetchContent(ctx, rawURL, http.MethodGet, "", f.timeout)
+}
+
+// RenderSubURL Resolves {{.Name}} placeholders in a subscription URL from vars
+// URLs without placeholders are returned unchanged, unknown variables are an error
+func RenderSubURL(rawURL string, vars map[string]string) (string, error) {
+	if !strings.Contains(rawURL, "{{") {
+		return rawURL, nil
+	}
+
+	tmpl, err := template.New("url").Option("missingkey=error").Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", model.ErrInvalidSubURL, err)
+	}
+
+	var buf strings.Builder
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("%w: %v", model.ErrInvalidSubURL, err)
+	}
+
+	return buf.String(), nil
+}
+
 // fetchContent Fetch URL content
-func (f *SubFetcher) fetchContent(ctx context.Context, subURL string) (string, error) {
+// method defaults to GET, payload is only sent with POST
+func (f *SubFetcher) fetchContent(ctx context.Context, subURL, method, payload string, timeout time.Duration) (string, error) {
 	// Validate URL
-	if _, err := url.ParseRequestURI(subURL); err != nil {
+	parsedURL, err := url.ParseRequestURI(subURL)
+	if err != nil {
 		return "", model.ErrInvalidSubURL
 	}
 
+	// The timeout covers waiting for a host slot, so a busy host cannot stall the caller indefinitely
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Wait for a free slot on this host
+	host := parsedURL.Hostname()
+	if err := f.hostLimiter.acquire(ctx, host); err != nil {
+		return "", classifyFetchError(fmt.Errorf("failed to wait for host slot: %w", err), model.FetchErrorConnect)
+	}
+	defer f.hostLimiter.release(host)
+
+	// Fetches queued for a host slot are not in flight yet
+	activeFetches.Add(1)
+	defer activeFetches.Add(-1)
+
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, subURL, nil)
+	var reqBody io.Reader
+	if method == http.MethodPost {
+		reqBody = strings.NewReader(payload)
+	} else {
+		method = http.MethodGet
+	}
+	req, err := http.NewRequestWithContext(ctx, method, subURL, reqBody)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
+	// Bodies looking like JSON are sent as JSON, anything else as a form
+	if payload != "" && method == http.MethodPost {
+		if trimmed := strings.TrimSpace(payload); strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+			req.Header.Set("Content-Type", "application/json")
+		} else {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+
 	// Set request header
 	req.Header.Set("User-Agent", "BestSub/1.0")
 
 	// Send request
 	resp, err := f.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		// Client errors quote the full URL, which ends up in logs and responses
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = logSafeURL(parsedURL)
+		}
+		return "", classifyFetchError(err, model.FetchErrorConnect)
 	}
 	defer resp.Body.Close()
 
 	// Check response status
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected response status: %d", resp.StatusCode)
+		return "", &model.FetchError{Kind: model.FetchErrorStatus, StatusCode: resp.StatusCode}
 	}
 
 	// Read response content
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return "", classifyFetchError(err, model.FetchErrorBody)
 	}
 
 	return string(body), nil
 }
+
+// logSafeURL Formats u as scheme and host only
+// Paths, queries and credentials of subscription URLs often carry tokens, e.g. rendered from URL variables
+func logSafeURL(u *url.URL) string {
+	safe := url.URL{Scheme: u.Scheme, Host: u.Host}
+	return safe.String()
+}
+
+// classifyFetchError Wraps err in a FetchError, timeouts and DNS failures get their own kind, anything else fallback
+func classifyFetchError(err error, fallback model.FetchErrorKind) error {
+	kind := fallback
+
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		kind = model.FetchErrorTimeout
+	case errors.As(err, &dnsErr):
+		kind = model.FetchErrorDNS
+	}
+
+	return &model.FetchError{Kind: kind, Err: err}
+}
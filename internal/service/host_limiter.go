@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// hostLimiter Limits the number of concurrent fetches against a single host
+type hostLimiter struct {
+	limit int
+	mu    sync.Mutex
+	slots map[string]*hostSlots
+}
+
+// hostSlots Semaphore of a single host
+type hostSlots struct {
+	sem chan struct{}
+	// users Fetches holding or waiting for a slot, the entry is removed once it drops to zero
+	users int
+}
+
+// newHostLimiter Creates a limiter allowing limit concurrent fetches per host, limit <= 0 disables limiting
+func newHostLimiter(limit int) *hostLimiter {
+	return &hostLimiter{
+		limit: limit,
+		slots: make(map[string]*hostSlots),
+	}
+}
+
+// acquire Blocks until a slot for host is free or ctx is done
+func (l *hostLimiter) acquire(ctx context.Context, host string) error {
+	if l.limit <= 0 {
+		return nil
+	}
+
+	slots := l.join(host)
+	select {
+	case slots.sem <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		l.leave(host, slots)
+		return ctx.Err()
+	}
+}
+
+// release Frees a slot previously taken by acquire
+func (l *hostLimiter) release(host string) {
+	if l.limit <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	slots := l.slots[host]
+	l.mu.Unlock()
+
+	<-slots.sem
+	l.leave(host, slots)
+}
+
+// join Registers a user of host's semaphore, creating it on first use
+func (l *hostLimiter) join(host string) *hostSlots {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slots, ok := l.slots[host]
+	if !ok {
+		slots = &hostSlots{sem: make(chan struct{}, l.limit)}
+		l.slots[host] = slots
+	}
+	slots.users++
+	return slots
+}
+
+// leave Unregisters a user of host's semaphore, idle hosts are dropped so the map does not grow with every host ever fetched
+func (l *hostLimiter) leave(host string, slots *hostSlots) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slots.users--
+	if slots.users == 0 {
+		delete(l.slots, host)
+	}
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/repository"
+)
+
+// trackedHosts Number of hosts the limiter currently keeps a semaphore for
+func (l *hostLimiter) trackedHosts() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.slots)
+}
+
+func TestHostLimiterBlocksOverLimit(t *testing.T) {
+	l := newHostLimiter(1)
+	ctx := context.Background()
+
+	if err := l.acquire(ctx, "a.example"); err != nil {
+		t.Fatalf("acquire error = %v", err)
+	}
+
+	// Another host is not affected
+	if err := l.acquire(ctx, "b.example"); err != nil {
+		t.Fatalf("acquire other host error = %v", err)
+	}
+	l.release("b.example")
+
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := l.acquire(waitCtx, "a.example"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("second acquire error = %v, want DeadlineExceeded", err)
+	}
+
+	l.release("a.example")
+	if err := l.acquire(ctx, "a.example"); err != nil {
+		t.Fatalf("acquire after release error = %v", err)
+	}
+	l.release("a.example")
+}
+
+func TestHostLimiterDropsIdleHosts(t *testing.T) {
+	l := newHostLimiter(1)
+	ctx := context.Background()
+
+	for i := 0; i < 50; i++ {
+		host := fmt.Sprintf("host-%d.example", i)
+		if err := l.acquire(ctx, host); err != nil {
+			t.Fatalf("acquire error = %v", err)
+		}
+		l.release(host)
+	}
+	if n := l.trackedHosts(); n != 0 {
+		t.Errorf("tracked hosts after release = %d, want 0", n)
+	}
+
+	// A waiter giving up does not leak the entry either
+	if err := l.acquire(ctx, "busy.example"); err != nil {
+		t.Fatalf("acquire error = %v", err)
+	}
+	waitCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.acquire(waitCtx, "busy.example"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled acquire error = %v, want Canceled", err)
+	}
+	if n := l.trackedHosts(); n != 1 {
+		t.Errorf("tracked hosts while held = %d, want 1", n)
+	}
+	l.release("busy.example")
+	if n := l.trackedHosts(); n != 0 {
+		t.Errorf("tracked hosts after release = %d, want 0", n)
+	}
+}
+
+func TestFetchSubRespectsPerHostLimit(t *testing.T) {
+	resetSubs(t)
+
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, "content of "+r.URL.Path)
+	}))
+	defer server.Close()
+
+	repo := repository.NewSubRepository(database.DB)
+	cfg := &model.Config{}
+	cfg.Fetcher.MaxConcurrentPerHost = 1
+	fetcher := NewSubFetcher(repo, cfg)
+
+	var subs []*model.Sub
+	for i := 0; i < 3; i++ {
+		subs = append(subs, createTestSub(t, repo, &model.Sub{URL: fmt.Sprintf("%s/sub%d", server.URL, i)}))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(subs))
+	for _, sub := range subs {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			if _, err := fetcher.FetchSub(context.Background(), id); err != nil {
+				errs <- err
+			}
+		}(sub.ID)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("FetchSub error = %v", err)
+	}
+	if got := maxInFlight.Load(); got != 1 {
+		t.Errorf("max concurrent fetches against the host = %d, want 1", got)
+	}
+	for i, sub := range subs {
+		content, err := GetSubContent(sub.ID)
+		if err != nil || content != fmt.Sprintf("content of /sub%d", i) {
+			t.Errorf("content of sub %d = %q, %v", sub.ID, content, err)
+		}
+	}
+}
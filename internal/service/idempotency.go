@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// IdempotencyKeyTTL How long a create request can be safely retried with the same key
+const IdempotencyKeyTTL = 24 * time.Hour
+
+type idempotencyEntry struct {
+	// done Closed once the request holding the key completed or released it
+	done       chan struct{}
+	resourceID int64
+	// expiresAt Zero while the request holding the key is still in flight
+	expiresAt time.Time
+}
+
+// IdempotencyStore In-memory map of idempotency keys to the resource they created
+type IdempotencyStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*idempotencyEntry
+}
+
+// NewIdempotencyStore Creates a store whose keys expire after ttl
+func NewIdempotencyStore(ttl time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		ttl:     ttl,
+		entries: make(map[string]*idempotencyEntry),
+	}
+}
+
+// IdempotencyReservation Claim on a key held by the request that arrived first
+type IdempotencyReservation struct {
+	store    *IdempotencyStore
+	key      string
+	entry    *idempotencyEntry
+	finished bool
+}
+
+// Reserve Claims key atomically, so concurrent requests with the same key cannot both create a resource
+// The first caller gets a reservation it must Complete or Release. Later callers wait until the first
+// request finished and get the resource ID it recorded, or the reservation if the first request failed.
+// Waiting stops with ctx's error when ctx is done first.
+func (s *IdempotencyStore) Reserve(ctx context.Context, key string) (*IdempotencyReservation, int64, error) {
+	for {
+		s.mu.Lock()
+		entry, ok := s.entries[key]
+		if ok && !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+			delete(s.entries, key)
+			ok = false
+		}
+
+		if !ok {
+			entry = &idempotencyEntry{done: make(chan struct{})}
+			s.entries[key] = entry
+			s.mu.Unlock()
+			return &IdempotencyReservation{store: s, key: key, entry: entry}, 0, nil
+		}
+		s.mu.Unlock()
+
+		select {
+		case <-entry.done:
+			s.mu.Lock()
+			resourceID := entry.resourceID
+			s.mu.Unlock()
+
+			// A released key is free again, try to claim it
+			if resourceID != 0 {
+				return nil, resourceID, nil
+			}
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		}
+	}
+}
+
+// Forget Drops key if it still records resourceID, e.g. because the resource was deleted since
+func (s *IdempotencyStore) Forget(key string, resourceID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && entry.resourceID == resourceID && !entry.expiresAt.IsZero() {
+		delete(s.entries, key)
+	}
+}
+
+// Complete Records the resource created for the reserved key and drops expired keys
+func (r *IdempotencyReservation) Complete(resourceID int64) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.finished {
+		return
+	}
+	r.finished = true
+
+	now := time.Now()
+	for k, entry := range s.entries {
+		if !entry.expiresAt.IsZero() && now.After(entry.expiresAt) {
+			delete(s.entries, k)
+		}
+	}
+
+	r.entry.resourceID = resourceID
+	r.entry.expiresAt = now.Add(s.ttl)
+	close(r.entry.done)
+}
+
+// Release Frees the key when the request did not create anything, no-op after Complete
+func (r *IdempotencyReservation) Release() {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.finished {
+		return
+	}
+	r.finished = true
+
+	if s.entries[r.key] == r.entry {
+		delete(s.entries, r.key)
+	}
+	close(r.entry.done)
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyReplay(t *testing.T) {
+	store := NewIdempotencyStore(time.Hour)
+	ctx := context.Background()
+
+	res, _, err := store.Reserve(ctx, "k")
+	if err != nil || res == nil {
+		t.Fatalf("first Reserve = %v, %v, want a reservation", res, err)
+	}
+	res.Complete(42)
+	res.Release()
+
+	res, id, err := store.Reserve(ctx, "k")
+	if err != nil || res != nil || id != 42 {
+		t.Fatalf("replayed Reserve = %v, %d, %v, want resource 42", res, id, err)
+	}
+
+	// Other keys are independent
+	if res, _, _ := store.Reserve(ctx, "other"); res == nil {
+		t.Error("Reserve of another key did not return a reservation")
+	}
+}
+
+func TestIdempotencyReleaseFreesKey(t *testing.T) {
+	store := NewIdempotencyStore(time.Hour)
+	ctx := context.Background()
+
+	res, _, _ := store.Reserve(ctx, "k")
+	res.Release()
+
+	res, id, err := store.Reserve(ctx, "k")
+	if err != nil || res == nil {
+		t.Fatalf("Reserve after Release = %v, %d, %v, want a new reservation", res, id, err)
+	}
+}
+
+func TestIdempotencyExpiry(t *testing.T) {
+	store := NewIdempotencyStore(10 * time.Millisecond)
+	ctx := context.Background()
+
+	res, _, _ := store.Reserve(ctx, "k")
+	res.Complete(7)
+	time.Sleep(20 * time.Millisecond)
+
+	if res, id, _ := store.Reserve(ctx, "k"); res == nil {
+		t.Errorf("Reserve after expiry replayed resource %d, want a new reservation", id)
+	}
+}
+
+func TestIdempotencyForget(t *testing.T) {
+	store := NewIdempotencyStore(time.Hour)
+	ctx := context.Background()
+
+	res, _, _ := store.Reserve(ctx, "k")
+	res.Complete(7)
+
+	// A different resource ID leaves the key alone
+	store.Forget("k", 8)
+	if _, id, _ := store.Reserve(ctx, "k"); id != 7 {
+		t.Fatalf("replayed resource = %d, want 7", id)
+	}
+
+	store.Forget("k", 7)
+	if res, _, _ := store.Reserve(ctx, "k"); res == nil {
+		t.Error("Reserve after Forget did not return a reservation")
+	}
+}
+
+func TestIdempotencyConcurrentReserve(t *testing.T) {
+	store := NewIdempotencyStore(time.Hour)
+	ctx := context.Background()
+
+	const requests = 20
+	var created atomic.Int32
+	ids := make([]int64, requests)
+
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			res, id, err := store.Reserve(ctx, "k")
+			if err != nil {
+				t.Errorf("Reserve error = %v", err)
+				return
+			}
+			if res != nil {
+				// Hold the key for a while so the other requests queue up
+				time.Sleep(20 * time.Millisecond)
+				created.Add(1)
+				id = 99
+				res.Complete(id)
+			}
+			ids[i] = id
+		}(i)
+	}
+	wg.Wait()
+
+	if n := created.Load(); n != 1 {
+		t.Errorf("resources created = %d, want 1", n)
+	}
+	for i, id := range ids {
+		if id != 99 {
+			t.Errorf("request %d got resource %d, want 99", i, id)
+		}
+	}
+}
+
+func TestIdempotencyWaitTimeout(t *testing.T) {
+	store := NewIdempotencyStore(time.Hour)
+
+	res, _, _ := store.Reserve(context.Background(), "k")
+	defer res.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := store.Reserve(ctx, "k"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Reserve while in flight error = %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestIdempotencyWaiterTakesOverReleasedKey(t *testing.T) {
+	store := NewIdempotencyStore(time.Hour)
+	ctx := context.Background()
+
+	first, _, _ := store.Reserve(ctx, "k")
+
+	done := make(chan *IdempotencyReservation)
+	go func() {
+		res, _, _ := store.Reserve(ctx, "k")
+		done <- res
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	first.Release()
+
+	select {
+	case res := <-done:
+		if res == nil {
+			t.Fatal("waiter did not get the released key")
+		}
+		res.Release()
+	case <-time.After(time.Second):
+		t.Fatal("waiter still blocked after the key was released")
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/logger"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "bestsub-service-test")
+	if err != nil {
+		panic(err)
+	}
+
+	dbConfig := database.DefaultConfig(filepath.Join(dir, "test.db"))
+	dbConfig.BcryptCost = bcrypt.MinCost
+	if err := database.InitDatabaseWithConfig(dbConfig); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+
+	database.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// resetSubs Empties the subs table and the content store
+func resetSubs(t *testing.T) {
+	t.Helper()
+
+	if _, err := database.DB.Exec("DELETE FROM subs"); err != nil {
+		t.Fatalf("failed to empty subs: %v", err)
+	}
+	ClearAllContent()
+}
+
+// createTestSub Stores sub, an empty cron defaults to hourly
+func createTestSub(t *testing.T, repo repository.SubRepository, sub *model.Sub) *model.Sub {
+	t.Helper()
+
+	if sub.Cron == "" {
+		sub.Cron = "0 */1 * * *"
+	}
+	if err := repo.Create(context.Background(), sub); err != nil {
+		t.Fatalf("failed to create sub %s: %v", sub.URL, err)
+	}
+	return sub
+}
+
+// logBuffer Concurrency-safe log destination
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// captureLogs Collects log output at debug level until the test ends
+func captureLogs(t *testing.T) *logBuffer {
+	t.Helper()
+
+	buf := &logBuffer{}
+	prevOutput := logger.SetOutput(buf)
+	prevLevel := logger.LogLevelSet
+	logger.LogLevelSet = logger.LogLevelDebug
+	t.Cleanup(func() {
+		logger.SetOutput(prevOutput)
+		logger.LogLevelSet = prevLevel
+	})
+	return buf
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bestruirui/bestsub/internal/model"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// argon2id parameters for new hashes, stored in the hash so they can change without breaking old ones
+const (
+	argon2Time    uint32 = 1
+	argon2Memory  uint32 = 64 * 1024
+	argon2Threads uint8  = 4
+	argon2KeyLen  uint32 = 32
+	argon2SaltLen        = 16
+)
+
+// argon2Prefix Algorithm prefix of argon2id hashes in PHC string format
+const argon2Prefix = "$argon2id$"
+
+var ErrUnknownHashAlgorithm = errors.New("unknown password hash algorithm")
+
+// HashPasswordWith Hashes password with the given algorithm, bcryptCost 0 uses bcrypt's default cost
+// bcrypt hashes keep their native $2a$ form, argon2id hashes use $argon2id$v=19$m=..,t=..,p=..$salt$hash
+func HashPasswordWith(algorithm string, bcryptCost int, password string) (string, error) {
+	switch algorithm {
+	case model.PasswordHashBcrypt:
+		hashed, err := bcrypt.GenerateFromPassword([]byte(password), effectiveBcryptCost(bcryptCost))
+		if err != nil {
+			return "", err
+		}
+		return string(hashed), nil
+	case model.PasswordHashArgon2id:
+		salt := make([]byte, argon2SaltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return "", fmt.Errorf("failed to generate salt: %w", err)
+		}
+		key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+		return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2Prefix, argon2.Version,
+			argon2Memory, argon2Time, argon2Threads,
+			base64.RawStdEncoding.EncodeToString(salt),
+			base64.RawStdEncoding.EncodeToString(key)), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownHashAlgorithm, algorithm)
+	}
+}
+
+// CheckPassword Reports whether password matches hashed, the algorithm is taken from the hash prefix
+func CheckPassword(hashed, password string) bool {
+	if strings.HasPrefix(hashed, argon2Prefix) {
+		return checkArgon2id(hashed, password)
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hashed), []byte(password)) == nil
+}
+
+// HashAlgorithm Returns the algorithm a stored hash was created with
+func HashAlgorithm(hashed string) string {
+	if strings.HasPrefix(hashed, argon2Prefix) {
+		return model.PasswordHashArgon2id
+	}
+	return model.PasswordHashBcrypt
+}
+
+// NeedsRehash Reports whether a stored hash differs from the given algorithm or bcrypt cost
+func NeedsRehash(hashed, algorithm string, bcryptCost int) bool {
+	if HashAlgorithm(hashed) != algorithm {
+		return true
+	}
+	if algorithm != model.PasswordHashBcrypt {
+		return false
+	}
+	cost, err := bcrypt.Cost([]byte(hashed))
+	return err == nil && cost != effectiveBcryptCost(bcryptCost)
+}
+
+// effectiveBcryptCost Maps the unset cost 0 to bcrypt's default
+func effectiveBcryptCost(cost int) int {
+	if cost == 0 {
+		return bcrypt.DefaultCost
+	}
+	return cost
+}
+
+// checkArgon2id Verifies an argon2id hash using the parameters stored in it
+func checkArgon2id(hashed, password string) bool {
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(hashed, "$")
+	if len(parts) != 6 {
+		return false
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false
+	}
+
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil {
+		return false
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, computed) == 1
+}
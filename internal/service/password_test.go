@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/bestruirui/bestsub/internal/database"
+	"github.com/bestruirui/bestsub/internal/model"
+	"github.com/bestruirui/bestsub/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashPasswordWith(t *testing.T) {
+	for _, algorithm := range []string{model.PasswordHashBcrypt, model.PasswordHashArgon2id} {
+		t.Run(algorithm, func(t *testing.T) {
+			hashed, err := HashPasswordWith(algorithm, bcrypt.MinCost, "secret")
+			if err != nil {
+				t.Fatalf("HashPasswordWith error = %v", err)
+			}
+			if got := HashAlgorithm(hashed); got != algorithm {
+				t.Errorf("HashAlgorithm = %q, want %q", got, algorithm)
+			}
+			if !CheckPassword(hashed, "secret") {
+				t.Error("CheckPassword rejected the right password")
+			}
+			if CheckPassword(hashed, "wrong") {
+				t.Error("CheckPassword accepted a wrong password")
+			}
+		})
+	}
+
+	if _, err := HashPasswordWith("md5", 0, "secret"); !errors.Is(err, ErrUnknownHashAlgorithm) {
+		t.Errorf("HashPasswordWith(md5) error = %v, want %v", err, ErrUnknownHashAlgorithm)
+	}
+}
+
+func TestAuthenticateUpgradesHashAlgorithm(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewUserRepository(database.DB)
+	bcryptSvc := NewUserService(repo, model.PasswordHashBcrypt, bcrypt.MinCost)
+	argon2Svc := NewUserService(repo, model.PasswordHashArgon2id, bcrypt.MinCost)
+
+	user, err := bcryptSvc.CreateUser(ctx, "hash-upgrade", "secret")
+	if err != nil {
+		t.Fatalf("CreateUser error = %v", err)
+	}
+	t.Cleanup(func() { repo.Delete(ctx, user.ID) })
+
+	storedAlgorithm := func() string {
+		t.Helper()
+		stored, err := repo.GetByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("GetByID error = %v", err)
+		}
+		return HashAlgorithm(stored.Password)
+	}
+
+	// A bcrypt hash still logs in once argon2id is configured and is moved to argon2id
+	if _, err := argon2Svc.Authenticate(ctx, "hash-upgrade", "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("Authenticate with wrong password error = %v, want %v", err, ErrInvalidCredentials)
+	}
+	if got := storedAlgorithm(); got != model.PasswordHashBcrypt {
+		t.Fatalf("algorithm after failed login = %q, want bcrypt kept", got)
+	}
+	if _, err := argon2Svc.Authenticate(ctx, "hash-upgrade", "secret"); err != nil {
+		t.Fatalf("Authenticate with bcrypt hash error = %v", err)
+	}
+	if got := storedAlgorithm(); got != model.PasswordHashArgon2id {
+		t.Errorf("algorithm after login = %q, want %q", got, model.PasswordHashArgon2id)
+	}
+
+	// Switching back works the same way
+	if _, err := bcryptSvc.Authenticate(ctx, "hash-upgrade", "secret"); err != nil {
+		t.Fatalf("Authenticate with argon2id hash error = %v", err)
+	}
+	if got := storedAlgorithm(); got != model.PasswordHashBcrypt {
+		t.Errorf("algorithm after login = %q, want %q", got, model.PasswordHashBcrypt)
+	}
+}
+
+func TestHashPasswordBcryptCost(t *testing.T) {
+	tests := []struct {
+		configured int
+		want       int
+	}{
+		{bcrypt.MinCost, bcrypt.MinCost},
+		{bcrypt.MinCost + 1, bcrypt.MinCost + 1},
+		{0, bcrypt.DefaultCost},
+	}
+
+	for _, tt := range tests {
+		hashed, err := HashPasswordWith(model.PasswordHashBcrypt, tt.configured, "secret")
+		if err != nil {
+			t.Fatalf("HashPasswordWith error = %v", err)
+		}
+		cost, err := bcrypt.Cost([]byte(hashed))
+		if err != nil {
+			t.Fatalf("bcrypt.Cost error = %v", err)
+		}
+		if cost != tt.want {
+			t.Errorf("cost of hash with configured cost %d = %d, want %d", tt.configured, cost, tt.want)
+		}
+		if NeedsRehash(hashed, model.PasswordHashBcrypt, tt.configured) {
+			t.Errorf("hash with configured cost %d needs a rehash", tt.configured)
+		}
+		if !NeedsRehash(hashed, model.PasswordHashBcrypt, tt.want+1) {
+			t.Errorf("hash with cost %d does not need a rehash for cost %d", cost, tt.want+1)
+		}
+	}
+}
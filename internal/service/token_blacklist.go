@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bestruirui/bestsub/internal/logger"
+	"github.com/bestruirui/bestsub/internal/repository"
+)
+
+// tokenBlacklistPruneInterval Time between removals of expired blacklist entries
+const tokenBlacklistPruneInterval = time.Hour
+
+var (
+	tokenBlacklistRepo repository.TokenBlacklistRepository
+	revokedTokens      = make(map[string]time.Time)
+	revokedTokensMutex sync.RWMutex
+)
+
+// InitTokenBlacklist Prunes expired entries and loads the persisted blacklist into memory
+// so tokens revoked before a restart stay invalid
+func InitTokenBlacklist(ctx context.Context, repo repository.TokenBlacklistRepository) error {
+	now := time.Now()
+	if _, err := repo.DeleteExpired(ctx, now); err != nil {
+		return err
+	}
+
+	revoked, err := repo.ListActive(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	revokedTokensMutex.Lock()
+	tokenBlacklistRepo = repo
+	revokedTokens = revoked
+	revokedTokensMutex.Unlock()
+
+	logger.Debug("Loaded %d revoked tokens", len(revoked))
+	return nil
+}
+
+// RevokeToken Blacklists the token ID until it expires
+// The entry is kept in memory even if persisting it fails
+func RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	revokedTokensMutex.Lock()
+	revokedTokens[jti] = expiresAt
+	repo := tokenBlacklistRepo
+	revokedTokensMutex.Unlock()
+
+	if repo == nil {
+		return nil
+	}
+	return repo.Add(ctx, jti, expiresAt)
+}
+
+// IsTokenRevoked Reports whether the token ID has been revoked
+func IsTokenRevoked(jti string) bool {
+	revokedTokensMutex.RLock()
+	defer revokedTokensMutex.RUnlock()
+
+	_, revoked := revokedTokens[jti]
+	return revoked
+}
+
+// StartTokenBlacklistPruner Periodically drops expired entries from memory and the database until ctx is cancelled
+func StartTokenBlacklistPruner(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(tokenBlacklistPruneInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				pruneRevokedTokens(ctx, now)
+			}
+		}
+	}()
+}
+
+// pruneRevokedTokens Drops entries that expired before now
+func pruneRevokedTokens(ctx context.Context, now time.Time) {
+	revokedTokensMutex.Lock()
+	for jti, expiresAt := range revokedTokens {
+		if !expiresAt.After(now) {
+			delete(revokedTokens, jti)
+		}
+	}
+	repo := tokenBlacklistRepo
+	revokedTokensMutex.Unlock()
+
+	if repo == nil {
+		return
+	}
+	if _, err := repo.DeleteExpired(ctx, now); err != nil {
+		logger.Error("Failed to prune token blacklist: %v", err)
+	}
+}
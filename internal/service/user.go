@@ -4,9 +4,10 @@ import (
 	"context"
 	"errors"
 
+	"github.com/bestruirui/bestsub/internal/logger"
 	"github.com/bestruirui/bestsub/internal/model"
 	"github.com/bestruirui/bestsub/internal/repository"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/bestruirui/bestsub/internal/validator"
 )
 
 var (
@@ -16,17 +17,27 @@ var (
 // UserService User related business logic service
 type UserService struct {
 	userRepo repository.UserRepository
+	// hashAlgorithm Algorithm used for new password hashes
+	hashAlgorithm string
+	// bcryptCost Cost of new bcrypt hashes, 0 uses bcrypt's default
+	bcryptCost int
 }
 
 // NewUserService Create a new user service instance
-func NewUserService(userRepo repository.UserRepository) *UserService {
+func NewUserService(userRepo repository.UserRepository, hashAlgorithm string, bcryptCost int) *UserService {
 	return &UserService{
-		userRepo: userRepo,
+		userRepo:      userRepo,
+		hashAlgorithm: hashAlgorithm,
+		bcryptCost:    bcryptCost,
 	}
 }
 
 // CreateUser Create a new user
 func (s *UserService) CreateUser(ctx context.Context, username, password string) (*model.User, error) {
+	if err := validator.ValidateUsername(username); err != nil {
+		return nil, err
+	}
+
 	// Create user object
 	user := &model.User{
 		Username: username,
@@ -63,6 +74,17 @@ func (s *UserService) Authenticate(ctx context.Context, username, password strin
 		return nil, ErrInvalidCredentials
 	}
 
+	// Transparently move the stored hash to the configured algorithm and cost, the login succeeds either way
+	if NeedsRehash(user.Password, s.hashAlgorithm, s.bcryptCost) {
+		if hashedPassword, err := s.HashPassword(password); err != nil {
+			logger.Warn("Failed to rehash password for user %d: %v", user.ID, err)
+		} else if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err != nil {
+			logger.Warn("Failed to store rehashed password for user %d: %v", user.ID, err)
+		} else {
+			user.Password = hashedPassword
+		}
+	}
+
 	return user, nil
 }
 
@@ -94,27 +116,20 @@ func (s *UserService) UpdateUserInfo(ctx context.Context, user *model.User) erro
 	return s.userRepo.Update(ctx, user)
 }
 
-// HashPassword Hash password
+// HashPassword Hash password with the configured algorithm
 func (s *UserService) HashPassword(password string) (string, error) {
-	// Use bcrypt algorithm to hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return "", err
-	}
-	return string(hashedPassword), nil
+	return HashPasswordWith(s.hashAlgorithm, s.bcryptCost, password)
 }
 
-// VerifyPassword Verify if password matches
+// VerifyPassword Verify if password matches, hashes of any supported algorithm are accepted
 func (s *UserService) VerifyPassword(hashedPassword, password string) bool {
-	// Compare provided password and stored hash
-	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-	return err == nil
+	return CheckPassword(hashedPassword, password)
 }
 
 // IsAdmin Check if user is an admin
 func (s *UserService) IsAdmin(user *model.User) bool {
 	// Simple implementation: User with ID 1 is considered an admin
-	return user.ID == 1
+	return user.ID == model.AdminUserID
 }
 
 // SanitizeUser Remove sensitive information, used for API response
@@ -0,0 +1,33 @@
+package validator
+
+import (
+	"errors"
+	"net"
+	"strings"
+)
+
+var ErrInvalidCIDR = errors.New("invalid IP address or CIDR")
+
+// ParseIPOrCIDR parses a CIDR notation or a bare IP, which is treated as a single-address network
+func ParseIPOrCIDR(entry string) (*net.IPNet, error) {
+	entry = strings.TrimSpace(entry)
+
+	if !strings.Contains(entry, "/") {
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, ErrInvalidCIDR
+		}
+		bits := 128
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 32
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, network, err := net.ParseCIDR(entry)
+	if err != nil {
+		return nil, ErrInvalidCIDR
+	}
+	return network, nil
+}
@@ -3,7 +3,9 @@ package validator
 import (
 	"errors"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 var (
@@ -69,3 +71,155 @@ func ValidateCron(cron string) error {
 
 	return nil
 }
+
+// cronSchedule Parsed cron expression, each field stored as a bit set of allowed values
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+// NextRuns returns the next count fire times of the cron expression after from
+func NextRuns(cron string, from time.Time, count int) ([]time.Time, error) {
+	if err := ValidateCron(cron); err != nil {
+		return nil, err
+	}
+
+	schedule, err := parseCron(cron)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]time.Time, 0, count)
+	t := from
+	for len(runs) < count {
+		next, ok := schedule.next(t)
+		if !ok {
+			break
+		}
+		runs = append(runs, next)
+		t = next
+	}
+
+	return runs, nil
+}
+
+// parseCron Parses a five-field cron expression into a schedule
+func parseCron(cron string) (*cronSchedule, error) {
+	parts := strings.Fields(cron)
+	if len(parts) != 5 {
+		return nil, ErrInvalidCronFormat
+	}
+
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+	var fields [5]uint64
+	for i, part := range parts {
+		bits, err := parseCronField(part, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = bits
+	}
+
+	return &cronSchedule{
+		minute:  fields[0],
+		hour:    fields[1],
+		dom:     fields[2],
+		month:   fields[3],
+		dow:     fields[4],
+		domStar: strings.HasPrefix(parts[2], "*"),
+		dowStar: strings.HasPrefix(parts[4], "*"),
+	}, nil
+}
+
+// parseCronField Parses a single cron field such as "*/5", "1-3" or "1,2,3"
+func parseCronField(field string, min, max int) (uint64, error) {
+	var bits uint64
+
+	for _, item := range strings.Split(field, ",") {
+		rangePart, step := item, 1
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			s, err := strconv.Atoi(item[idx+1:])
+			if err != nil || s <= 0 {
+				return 0, ErrInvalidCronValue
+			}
+			rangePart, step = item[:idx], s
+		}
+
+		start, end := min, max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.Split(rangePart, "-")
+			if len(bounds) != 2 {
+				return 0, ErrInvalidCronValue
+			}
+			var err error
+			if start, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, ErrInvalidCronValue
+			}
+			if end, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, ErrInvalidCronValue
+			}
+		default:
+			v, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, ErrInvalidCronValue
+			}
+			start = v
+			if step == 1 {
+				end = v
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return 0, ErrInvalidCronValue
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, nil
+}
+
+// next Returns the first fire time strictly after t
+// Gives up after five years, which only happens for expressions like "0 0 30 2 *"
+func (s *cronSchedule) next(t time.Time) (time.Time, bool) {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	loc := t.Location()
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t, true
+	}
+
+	return time.Time{}, false
+}
+
+// dayMatches Applies the standard cron rule: when both day fields are restricted, either may match
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
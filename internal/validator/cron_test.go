@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNextRuns(t *testing.T) {
+	// Sunday
+	from := time.Date(2025, 1, 5, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		cron string
+		want []time.Time
+	}{
+		{
+			name: "every 15 minutes",
+			cron: "*/15 * * * *",
+			want: []time.Time{
+				time.Date(2025, 1, 5, 10, 15, 0, 0, time.UTC),
+				time.Date(2025, 1, 5, 10, 30, 0, 0, time.UTC),
+				time.Date(2025, 1, 5, 10, 45, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "hourly default",
+			cron: "0 */1 * * *",
+			want: []time.Time{
+				time.Date(2025, 1, 5, 11, 0, 0, 0, time.UTC),
+				time.Date(2025, 1, 5, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "weekdays at nine",
+			cron: "0 9 * * 1-5",
+			want: []time.Time{
+				time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC),
+				time.Date(2025, 1, 7, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "first of the month",
+			cron: "30 2 1 * *",
+			want: []time.Time{
+				time.Date(2025, 2, 1, 2, 30, 0, 0, time.UTC),
+				time.Date(2025, 3, 1, 2, 30, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NextRuns(tt.cron, from, len(tt.want))
+			if err != nil {
+				t.Fatalf("NextRuns(%q) error = %v", tt.cron, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("NextRuns(%q) returned %d runs, want %d", tt.cron, len(got), len(tt.want))
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("run %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNextRunsInvalid(t *testing.T) {
+	tests := []struct {
+		cron string
+		want error
+	}{
+		{"* * *", ErrInvalidCronFormat},
+		{"* * * * * *", ErrInvalidCronFormat},
+		{"a * * * *", ErrInvalidCronFormat},
+		{"61 * * * *", ErrInvalidCronValue},
+		{"0 24 * * *", ErrInvalidCronValue},
+		{"*/0 * * * *", ErrInvalidCronValue},
+		{"5-1 * * * *", ErrInvalidCronValue},
+	}
+
+	for _, tt := range tests {
+		runs, err := NextRuns(tt.cron, time.Now(), 3)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("NextRuns(%q) error = %v, want %v", tt.cron, err, tt.want)
+		}
+		if runs != nil {
+			t.Errorf("NextRuns(%q) runs = %v, want nil", tt.cron, runs)
+		}
+	}
+}
+
+func TestNextRunsImpossibleDate(t *testing.T) {
+	runs, err := NextRuns("0 0 30 2 *", time.Now(), 3)
+	if err != nil {
+		t.Fatalf("NextRuns error = %v", err)
+	}
+	if len(runs) != 0 {
+		t.Errorf("runs = %v, want none for February 30th", runs)
+	}
+}
@@ -0,0 +1,25 @@
+package validator
+
+import (
+	"errors"
+	"net/url"
+)
+
+var (
+	ErrInvalidURL       = errors.New("invalid URL")
+	ErrInvalidURLScheme = errors.New("URL scheme must be http or https")
+)
+
+// ValidateSubURL validates that raw is an absolute http(s) URL with a host
+func ValidateSubURL(raw string) error {
+	u, err := url.ParseRequestURI(raw)
+	if err != nil || u.Host == "" {
+		return ErrInvalidURL
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ErrInvalidURLScheme
+	}
+
+	return nil
+}
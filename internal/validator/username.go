@@ -0,0 +1,35 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+const (
+	// MinUsernameLength Minimum number of characters in a username
+	MinUsernameLength = 3
+	// MaxUsernameLength Maximum number of characters in a username
+	MaxUsernameLength = 32
+)
+
+var (
+	ErrInvalidUsernameLength = fmt.Errorf("username must be between %d and %d characters", MinUsernameLength, MaxUsernameLength)
+	ErrInvalidUsernameChars  = errors.New("username may only contain letters, digits, '_' and '-'")
+)
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ValidateUsername validates the username length and character set
+// Allowed characters: ASCII letters, digits, _ and -
+func ValidateUsername(name string) error {
+	if len(name) < MinUsernameLength || len(name) > MaxUsernameLength {
+		return ErrInvalidUsernameLength
+	}
+
+	if !usernamePattern.MatchString(name) {
+		return ErrInvalidUsernameChars
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package validator
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateUsername(t *testing.T) {
+	tests := []struct {
+		name string
+		want error
+	}{
+		{"admin", nil},
+		{"ops_team-2", nil},
+		{strings.Repeat("a", MinUsernameLength), nil},
+		{strings.Repeat("a", MaxUsernameLength), nil},
+		{"ab", ErrInvalidUsernameLength},
+		{strings.Repeat("a", MaxUsernameLength+1), ErrInvalidUsernameLength},
+		{strings.Repeat("a", 10000), ErrInvalidUsernameLength},
+		{"john doe", ErrInvalidUsernameChars},
+		{"admin\x00", ErrInvalidUsernameChars},
+		{"line\nbreak", ErrInvalidUsernameChars},
+		{"user@example", ErrInvalidUsernameChars},
+		{"名字名字", ErrInvalidUsernameChars},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateUsername(tt.name); !errors.Is(err, tt.want) {
+			t.Errorf("ValidateUsername(%q) = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}